@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-git/go-billy/v5/osfs"
@@ -47,8 +50,22 @@ func run(args []string, logWriter *os.File) int {
 		return 1
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	chartReleaser := releaser.New(logger, conf, repo, commitParser)
-	if err := chartReleaser.Run(); err != nil {
+
+	if conf.Command == config.CommandPublish {
+		if err := chartReleaser.Publish(ctx, conf.PublishTag); err != nil {
+			logger.Err(err).Msg("failed to publish")
+
+			return 1
+		}
+
+		return 0
+	}
+
+	if err := chartReleaser.Run(ctx); err != nil {
 		logger.Err(err).Msg("failed to run releaser")
 
 		return 1
@@ -0,0 +1,45 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const asciiDocPlaceholder = "// INSERT COMMENT"
+
+// AsciiDoc renders the changelog like conventional-changelog in AsciiDoc syntax.
+type AsciiDoc struct{}
+
+var asciiDocMarkup = markup{
+	heading: func(level int, text string) string {
+		return strings.Repeat("=", level) + " " + text + "\n\n"
+	},
+	link: func(text, url string) string {
+		return fmt.Sprintf("link:%s[%s]", url, text)
+	},
+	bold: func(text string) string {
+		return "*" + text + "*"
+	},
+	bullet: func(level int) string {
+		return strings.Repeat("*", level) + " "
+	},
+}
+
+func (AsciiDoc) Render(c *Changelog) string {
+	return renderConventional(c, asciiDocMarkup)
+}
+
+func (r AsciiDoc) Write(c *Changelog, file io.ReadWriteSeeker) error {
+	header := "= Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" + asciiDocPlaceholder + "\n"
+
+	return insertAtPlaceholder(file, header, asciiDocPlaceholder, r.Render(c))
+}
+
+func (AsciiDoc) Extract(file []byte, version string) (string, bool) {
+	return extractRelease(file, version)
+}
+
+func (AsciiDoc) FileName() string {
+	return "CHANGELOG.adoc"
+}
@@ -2,4 +2,7 @@ package changelog
 
 import "errors"
 
-var ErrMissingPlaceholder = errors.New("changelog file does not contain <!-- INSERT COMMENT -->")
+var (
+	ErrMissingPlaceholder = errors.New("changelog file does not contain the INSERT COMMENT placeholder")
+	ErrUnknownFormat      = errors.New("unknown changelog format")
+)
@@ -0,0 +1,165 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// IssuePattern links references to an issue tracker, e.g. Jira keys or GitHub issues.
+type IssuePattern struct {
+	// Regexp matches the reference. The whole match is used as link text.
+	Regexp *regexp.Regexp
+	// URL is the link target. Submatches can be used like in regexp.Expand, e.g. https://jira.example.com/browse/$1.
+	URL string
+}
+
+// Footer is a git trailer of the commit message, e.g. Closes #45 or Refs: OPS-1234.
+type Footer struct {
+	Key   string
+	Value string
+}
+
+// issue is a reference to an issue found in the changelog.
+type issue struct {
+	text string
+	url  string
+}
+
+// AddIssuePattern adds a pattern to link references to an issue tracker in messages and footers.
+// Patterns are applied in the order of addition.
+func (c *Changelog) AddIssuePattern(pattern IssuePattern) {
+	c.issuePatterns = append(c.issuePatterns, pattern)
+}
+
+// getIssuePatterns returns the configured patterns and the default pattern of the remote.
+func (c *Changelog) getIssuePatterns() []IssuePattern {
+	if c.links.issueURL == "" {
+		return c.issuePatterns
+	}
+
+	return append(slices.Clip(c.issuePatterns), IssuePattern{Regexp: regexpIssueNumber, URL: c.links.issueURL})
+}
+
+// findIssues returns all non-overlapping issue references in the text, in order of appearance.
+// If multiple patterns match at the same position, the first pattern wins.
+func (c *Changelog) findIssues(text string) ([][]int, []issue) {
+	var (
+		locations [][]int
+		issues    []issue
+	)
+
+	for _, pattern := range c.getIssuePatterns() {
+		for _, match := range pattern.Regexp.FindAllStringSubmatchIndex(text, -1) {
+			if slices.ContainsFunc(locations, func(loc []int) bool { return match[0] < loc[1] && loc[0] < match[1] }) {
+				continue
+			}
+
+			url := string(pattern.Regexp.ExpandString(nil, pattern.URL, text, match))
+
+			locations = append(locations, match[:2])
+			issues = append(issues, issue{text: text[match[0]:match[1]], url: url})
+		}
+	}
+
+	order := make([]int, len(locations))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortFunc(order, func(a, b int) int { return locations[a][0] - locations[b][0] })
+
+	sortedLocations := make([][]int, len(order))
+	sortedIssues := make([]issue, len(order))
+
+	for i, j := range order {
+		sortedLocations[i] = locations[j]
+		sortedIssues[i] = issues[j]
+	}
+
+	return sortedLocations, sortedIssues
+}
+
+// linkIssues replaces all issue references in the text with links.
+func (c *Changelog) linkIssues(text string, m markup) string {
+	locations, issues := c.findIssues(text)
+	if len(issues) == 0 {
+		return text
+	}
+
+	sb := &strings.Builder{}
+	last := 0
+
+	for i, loc := range locations {
+		sb.WriteString(text[last:loc[0]])
+		sb.WriteString(m.link(issues[i].text, issues[i].url))
+
+		last = loc[1]
+	}
+
+	sb.WriteString(text[last:])
+
+	return sb.String()
+}
+
+// footerReferences returns the footers containing issue references, rendered like ", closes [#45](url)".
+func (c *Changelog) footerReferences(footers []Footer, m markup) string {
+	sb := &strings.Builder{}
+
+	for _, footer := range footers {
+		_, issues := c.findIssues(footer.Value)
+		if len(issues) == 0 {
+			continue
+		}
+
+		links := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			links = append(links, m.link(issue.text, issue.url))
+		}
+
+		sb.WriteString(fmt.Sprintf(", %s %s", strings.ToLower(footer.Key), strings.Join(links, ", ")))
+	}
+
+	return sb.String()
+}
+
+// issues returns all unique issue references of the release, sorted by text.
+func (c *Changelog) issues() []issue {
+	var issues []issue
+
+	for _, entries := range [][]Entry{c.breaking, c.features, c.fixes} {
+		for _, entry := range entries {
+			message, _ := c.splitPRSuffix(entry.text())
+			_, found := c.findIssues(message)
+			issues = append(issues, found...)
+
+			for _, footer := range entry.Footers {
+				_, found = c.findIssues(footer.Value)
+				issues = append(issues, found...)
+			}
+		}
+	}
+
+	slices.SortFunc(issues, func(a, b issue) int { return strings.Compare(a.text, b.text) })
+
+	return slices.Compact(issues)
+}
+
+// writeIssues writes a section with all issue references of the release.
+func (c *Changelog) writeIssues(sb *strings.Builder, m markup) {
+	issues := c.issues()
+	if len(issues) == 0 {
+		return
+	}
+
+	sb.WriteString(m.heading(3, "Issues"))
+
+	for _, issue := range issues {
+		sb.WriteString(m.bullet(1))
+		sb.WriteString(m.link(issue.text, issue.url))
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+}
@@ -0,0 +1,205 @@
+package changelog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// JSON renders the changelog as structured JSON document.
+// The changelog file contains a JSON array of all releases, the newest release is appended.
+type JSON struct{}
+
+type jsonRelease struct {
+	Version         string      `json:"version"`
+	PreviousVersion string      `json:"previousVersion,omitempty"`
+	Date            string      `json:"date"`
+	CompareURL      string      `json:"compareUrl,omitempty"`
+	Breaking        []jsonEntry `json:"breaking"`
+	Features        []jsonEntry `json:"features"`
+	Fixes           []jsonEntry `json:"fixes"`
+	Issues          []jsonIssue `json:"issues,omitempty"`
+	Contributors    []string    `json:"contributors,omitempty"`
+}
+
+type jsonEntry struct {
+	Hash        string       `json:"hash"`
+	CommitURL   string       `json:"commitUrl,omitempty"`
+	Message     string       `json:"message"`
+	Type        string       `json:"type,omitempty"`
+	Scope       string       `json:"scope,omitempty"`
+	Description string       `json:"description,omitempty"`
+	Author      *jsonPerson  `json:"author,omitempty"`
+	CoAuthors   []jsonPerson `json:"coAuthors,omitempty"`
+	Footers     []jsonFooter `json:"footers,omitempty"`
+	Issues      []jsonIssue  `json:"issues,omitempty"`
+}
+
+type jsonPerson struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username,omitempty"`
+}
+
+type jsonFooter struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type jsonIssue struct {
+	Reference string `json:"reference"`
+	URL       string `json:"url"`
+}
+
+func (JSON) Render(c *Changelog) string {
+	// the release contains only strings and slices, which can always be encoded.
+	data, _ := json.MarshalIndent(newJSONRelease(c), "", "  ") //nolint:errchkjson
+
+	return string(data) + "\n"
+}
+
+func (JSON) Write(c *Changelog, file io.ReadWriteSeeker) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var releases []json.RawMessage
+
+	if strings.TrimSpace(string(data)) != "" {
+		if err = json.Unmarshal(data, &releases); err != nil {
+			return fmt.Errorf("failed to JSON decode changelog: %w", err)
+		}
+	}
+
+	release, err := json.Marshal(newJSONRelease(c))
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode release: %w", err)
+	}
+
+	releases = append(releases, release)
+
+	data, err = json.MarshalIndent(releases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to JSON encode changelog: %w", err)
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	if _, err = file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+
+	return nil
+}
+
+// Extract returns the release of the version from the JSON array of all releases.
+func (JSON) Extract(file []byte, version string) (string, bool) {
+	var releases []json.RawMessage
+
+	if err := json.Unmarshal(file, &releases); err != nil {
+		return "", false
+	}
+
+	for _, release := range releases {
+		var header struct {
+			Version string `json:"version"`
+		}
+
+		if err := json.Unmarshal(release, &header); err != nil || header.Version != version {
+			continue
+		}
+
+		var sb bytes.Buffer
+
+		if err := json.Indent(&sb, release, "", "  "); err != nil {
+			return "", false
+		}
+
+		return sb.String() + "\n", true
+	}
+
+	return "", false
+}
+
+func (JSON) FileName() string {
+	return "changelog.json"
+}
+
+func newJSONRelease(c *Changelog) jsonRelease {
+	release := jsonRelease{
+		Version:         c.newVersion,
+		PreviousVersion: c.oldVersion,
+		Date:            c.getDate(),
+		CompareURL:      c.getCompareLink(),
+		Breaking:        c.newJSONEntries(c.breaking),
+		Features:        c.newJSONEntries(c.features),
+		Fixes:           c.newJSONEntries(c.fixes),
+	}
+
+	if c.issuesList {
+		for _, issue := range c.issues() {
+			release.Issues = append(release.Issues, jsonIssue{Reference: issue.text, URL: issue.url})
+		}
+	}
+
+	if c.contributors {
+		release.Contributors = c.contributorNames()
+	}
+
+	return release
+}
+
+func (c *Changelog) newJSONEntries(entries []Entry) []jsonEntry {
+	if c.sort {
+		entries = sortEntries(entries)
+	}
+
+	jsonEntries := make([]jsonEntry, 0, len(entries))
+
+	for _, entry := range entries {
+		jsonEntry := jsonEntry{
+			Hash:        entry.Hash,
+			CommitURL:   c.getCommitLink(entry.Hash),
+			Message:     entry.Message,
+			Type:        entry.Type,
+			Scope:       entry.Scope,
+			Description: entry.Description,
+		}
+
+		if entry.Author.Name != "" {
+			author := c.newJSONPerson(entry.Author)
+			jsonEntry.Author = &author
+		}
+
+		for _, coAuthor := range entry.CoAuthors {
+			jsonEntry.CoAuthors = append(jsonEntry.CoAuthors, c.newJSONPerson(coAuthor))
+		}
+
+		message, _ := c.splitPRSuffix(entry.text())
+		_, issues := c.findIssues(message)
+
+		for _, footer := range entry.Footers {
+			jsonEntry.Footers = append(jsonEntry.Footers, jsonFooter(footer))
+
+			_, footerIssues := c.findIssues(footer.Value)
+			issues = append(issues, footerIssues...)
+		}
+
+		for _, issue := range issues {
+			jsonEntry.Issues = append(jsonEntry.Issues, jsonIssue{Reference: issue.text, URL: issue.url})
+		}
+
+		jsonEntries = append(jsonEntries, jsonEntry)
+	}
+
+	return jsonEntries
+}
+
+func (c *Changelog) newJSONPerson(person Person) jsonPerson {
+	return jsonPerson{Name: person.Name, Email: person.Email, Username: c.usernames[strings.ToLower(person.Email)]}
+}
@@ -0,0 +1,88 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// KeepAChangelog renders the changelog in the format of https://keepachangelog.com.
+//
+// Features are listed as Added, fixes as Fixed and breaking changes as Changed.
+// Breaking changes starting with "remove" are listed as Removed.
+type KeepAChangelog struct{}
+
+var keepAChangelogMarkup = markup{
+	heading: markdownMarkup.heading,
+	link:    markdownMarkup.link,
+	bold:    markdownMarkup.bold,
+	bullet: func(level int) string {
+		return strings.Repeat("  ", level-1) + "- "
+	},
+}
+
+func (KeepAChangelog) Render(c *Changelog) string {
+	m := keepAChangelogMarkup
+	sb := &strings.Builder{}
+
+	sb.WriteString(m.heading(2, fmt.Sprintf("[%s] - %s", c.newVersion, c.getDate())))
+
+	var changed, removed []Entry
+
+	for _, entry := range c.breaking {
+		if strings.HasPrefix(strings.ToLower(entry.text()), "remove") {
+			removed = append(removed, entry)
+		} else {
+			changed = append(changed, entry)
+		}
+	}
+
+	for _, section := range []struct {
+		header  string
+		entries []Entry
+	}{
+		{"Added", c.features},
+		{"Changed", changed},
+		{"Removed", removed},
+		{"Fixed", c.fixes},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		sb.WriteString(m.heading(3, section.header))
+		c.writeEntries(sb, section.entries, m)
+		sb.WriteString("\n")
+	}
+
+	if c.issuesList {
+		c.writeIssues(sb, m)
+	}
+
+	if c.contributors {
+		c.writeContributors(sb, m)
+	}
+
+	if link := c.getCompareLink(); link != "" {
+		sb.WriteString(fmt.Sprintf("[%s]: %s\n\n", c.newVersion, link))
+	}
+
+	return sb.String()
+}
+
+func (r KeepAChangelog) Write(c *Changelog, file io.ReadWriteSeeker) error {
+	header := "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" +
+		"The format is based on [Keep a Changelog](https://keepachangelog.com/en/1.1.0/),\n" +
+		"and this project adheres to [Semantic Versioning](https://semver.org/spec/v2.0.0.html).\n\n" +
+		markdownPlaceholder + "\n"
+
+	return insertAtPlaceholder(file, header, markdownPlaceholder, r.Render(c))
+}
+
+func (KeepAChangelog) Extract(file []byte, version string) (string, bool) {
+	return extractRelease(file, version)
+}
+
+func (KeepAChangelog) FileName() string {
+	return "CHANGELOG.md"
+}
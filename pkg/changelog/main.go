@@ -1,41 +1,81 @@
 package changelog
 
 import (
-	"bytes"
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 )
 
 type Changelog struct {
-	newVersion string
-	oldVersion string
-	links      links
-	breaking   []commit
-	fixes      []commit
-	features   []commit
+	newVersion   string
+	oldVersion   string
+	links        links
+	authors      bool
+	contributors bool
+	usernames    map[string]string
+	issuesList   bool
+	groupByScope bool
+	sort         bool
+	date         time.Time
+	dateFormat   string
+	renderer     Renderer
+	// issuePatterns link references to issue trackers.
+	issuePatterns []IssuePattern
+	breaking      []Entry
+	fixes         []Entry
+	features      []Entry
 }
 
-type commit struct {
-	hash    string
-	message string
+// Section is a section of the changelog.
+type Section int
+
+const (
+	SectionBreaking Section = iota
+	SectionFeatures
+	SectionFixes
+)
+
+// Entry is a single entry of the changelog.
+type Entry struct {
+	Hash string
+	// Message is the first line of the commit message.
+	Message string
+	// Type, Scope and Description are the parsed components of a conventional commit message.
+	// If Description is empty, the Message is rendered as is.
+	Type        string
+	Scope       string
+	Description string
+	Author      Person
+	CoAuthors   []Person
+	Footers     []Footer
+}
+
+// Person is an author or co-author of a commit.
+type Person struct {
+	Name  string
+	Email string
 }
 
 type links struct {
 	compareURL string
 	prURL      string
+	issueURL   string
 	commitURL  string
 }
 
 var (
 	regexpPRNumber        = regexp.MustCompile(`\(#(\d+)\)$`)
+	regexpIssueNumber     = regexp.MustCompile(`#(\d+)\b`)
 	regexpGithubRepoInfos = regexp.MustCompile(`^(?:https://github\.com/|git@github\.com:|ssh://git@github\.com/)([^/:]+/[^/:]+?)(?:\.git)?$`)
 )
 
+const DefaultDateFormat = "2006-01-02"
+
 func New() *Changelog {
-	return &Changelog{}
+	return &Changelog{dateFormat: DefaultDateFormat, renderer: Markdown{}}
 }
 
 func (c *Changelog) Len() int {
@@ -50,6 +90,7 @@ func (c *Changelog) SetRemote(remoteURL string) {
 
 		c.links.compareURL = fmt.Sprintf("https://github.com/%s/compare/%s", matches[1], "%s...%s")
 		c.links.prURL = fmt.Sprintf("https://github.com/%s/pull/$1", matches[1])
+		c.links.issueURL = fmt.Sprintf("https://github.com/%s/issues/$1", matches[1])
 		c.links.commitURL = fmt.Sprintf("https://github.com/%s/commit/%s", matches[1], "%s")
 	}
 }
@@ -62,20 +103,52 @@ func (c *Changelog) SetNewVersion(version string) {
 	c.newVersion = version
 }
 
+// SetAuthors enables the attribution of the author and co-authors on each entry.
+func (c *Changelog) SetAuthors(enabled bool) {
+	c.authors = enabled
+}
+
+// SetContributors enables a section listing all authors and co-authors of the release.
+func (c *Changelog) SetContributors(enabled bool) {
+	c.contributors = enabled
+}
+
+// SetUsernames sets the mapping from email addresses to usernames of the forge, e.g. GitHub.
+// Persons without username are rendered by name.
+func (c *Changelog) SetUsernames(usernames map[string]string) {
+	c.usernames = make(map[string]string, len(usernames))
+
+	for email, username := range usernames {
+		c.usernames[strings.ToLower(email)] = strings.TrimPrefix(username, "@")
+	}
+}
+
 func (c *Changelog) AddBreaking(message, hash string) {
-	c.breaking = append(c.breaking, commit{hash: hash, message: c.decorateMessage(message)})
+	c.Add(SectionBreaking, Entry{Hash: hash, Message: message})
 }
 
 func (c *Changelog) AddFix(message, hash string) {
-	c.fixes = append(c.fixes, commit{hash: hash, message: c.decorateMessage(message)})
+	c.Add(SectionFixes, Entry{Hash: hash, Message: message})
 }
 
 func (c *Changelog) AddFeature(message, hash string) {
-	c.features = append(c.features, commit{hash: hash, message: c.decorateMessage(message)})
+	c.Add(SectionFeatures, Entry{Hash: hash, Message: message})
+}
+
+// Add adds the entry to the given section.
+func (c *Changelog) Add(section Section, entry Entry) {
+	switch section {
+	case SectionBreaking:
+		c.breaking = append(c.breaking, entry)
+	case SectionFeatures:
+		c.features = append(c.features, entry)
+	case SectionFixes:
+		c.fixes = append(c.fixes, entry)
+	}
 }
 
 func (c *Changelog) getCompareLink() string {
-	if c.links.compareURL == "" {
+	if c.links.compareURL == "" || c.oldVersion == "" {
 		return ""
 	}
 
@@ -90,104 +163,140 @@ func (c *Changelog) getCommitLink(hash string) string {
 	return fmt.Sprintf(c.links.commitURL, hash)
 }
 
-// decorateMessage decorates the message with links to PRs if possible.
-func (c *Changelog) decorateMessage(message string) string {
-	if c.links.prURL == "" {
-		return message
-	}
+// decorateMessage decorates the message with links to PRs and issues if possible.
+func (c *Changelog) decorateMessage(message string, m markup) string {
+	message, prNumber := c.splitPRSuffix(message)
+	message = c.linkIssues(message, m)
 
-	//nolint:gocritic // more options coming
-	switch {
-	case regexpPRNumber.MatchString(message):
-		return regexpPRNumber.ReplaceAllString(message, fmt.Sprintf("([#$1](%s))", c.links.prURL))
+	if prNumber != "" {
+		message += fmt.Sprintf(" (%s)", m.link("#"+prNumber, strings.ReplaceAll(c.links.prURL, "$1", prNumber)))
 	}
 
 	return message
 }
 
-func (c *Changelog) String() string {
-	if c.Len() == 0 {
-		return ""
+// splitPRSuffix splits a trailing PR reference like (#123) from the message, if PR links are available.
+func (c *Changelog) splitPRSuffix(message string) (string, string) {
+	if c.links.prURL == "" {
+		return message, ""
 	}
 
-	sb := &strings.Builder{}
+	match := regexpPRNumber.FindStringSubmatchIndex(message)
+	if match == nil {
+		return message, ""
+	}
 
-	date := time.Now().Format("2006-01-02")
-	link := c.getCompareLink()
+	return strings.TrimRight(message[:match[0]], " "), message[match[2]:match[3]]
+}
 
-	if strings.HasSuffix(c.newVersion, ".0") {
-		sb.WriteString("## ")
-	} else {
-		sb.WriteString("### ")
-	}
+// SetGroupByScope enables grouping of entries by scope inside each section.
+func (c *Changelog) SetGroupByScope(enabled bool) {
+	c.groupByScope = enabled
+}
 
-	if link != "" {
-		sb.WriteString(fmt.Sprintf("[%s](%s) (%s)\n\n", c.newVersion, link, date))
-	} else {
-		sb.WriteString(fmt.Sprintf("%s (%s)\n\n", c.newVersion, date))
-	}
+// SetSort enables sorting of entries by scope and description inside each section.
+func (c *Changelog) SetSort(enabled bool) {
+	c.sort = enabled
+}
 
-	c.writeSection(sb, "⚠ BREAKING CHANGES", c.breaking)
-	c.writeSection(sb, "Features", c.features)
-	c.writeSection(sb, "Bug Fixes", c.fixes)
+// SetDate sets the release date. If not set, the current time is used.
+func (c *Changelog) SetDate(date time.Time) {
+	c.date = date
+}
 
-	return sb.String()
+// SetDateFormat sets the layout of the release date, see time.Layout.
+func (c *Changelog) SetDateFormat(layout string) {
+	c.dateFormat = layout
 }
 
-func (c *Changelog) writeSection(sb *strings.Builder, header string, entries []commit) {
-	if len(entries) == 0 {
-		return
+// Date returns the release date. If not set, the current time is returned.
+func (c *Changelog) Date() time.Time {
+	if c.date.IsZero() {
+		return time.Now()
 	}
 
-	sb.WriteString("### ")
-	sb.WriteString(header)
-	sb.WriteString("\n\n")
+	return c.date
+}
 
-	for _, entry := range entries {
-		link := c.getCommitLink(entry.hash)
+// getDate returns the formatted release date.
+func (c *Changelog) getDate() string {
+	return c.Date().Format(c.dateFormat)
+}
 
-		if link != "" {
-			sb.WriteString(fmt.Sprintf("* %s ([%s](%s))", entry.message, entry.hash, link))
-		} else {
-			sb.WriteString(fmt.Sprintf("* %s (%s)", entry.message, entry.hash))
-		}
+// SetIssuesList enables a section listing all issue references of the release.
+func (c *Changelog) SetIssuesList(enabled bool) {
+	c.issuesList = enabled
+}
 
-		sb.WriteString("\n")
+// String returns the changelog of the release, rendered by the configured renderer.
+func (c *Changelog) String() string {
+	if c.Len() == 0 {
+		return ""
 	}
 
-	sb.WriteString("\n")
+	return c.renderer.Render(c)
 }
 
+// WriteTo inserts the changelog of the release into the changelog file, rendered by the configured renderer.
 func (c *Changelog) WriteTo(filePath io.ReadWriteSeeker) error {
-	data, err := io.ReadAll(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
+	return c.renderer.Write(c, filePath) //nolint:wrapcheck
+}
+
+// FileName returns the name of the changelog file of the configured renderer.
+func (c *Changelog) FileName() string {
+	return c.renderer.FileName()
+}
+
+// Extract returns the release of the new version from the changelog file, rendered by the configured renderer.
+func (c *Changelog) Extract(file []byte) (string, bool) {
+	return c.renderer.Extract(file, c.newVersion)
+}
 
-	if len(data) == 0 {
-		changelog := "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n<!-- INSERT COMMENT -->\n" + c.String()
+// SetRenderer sets the renderer of the changelog. Defaults to Markdown.
+func (c *Changelog) SetRenderer(renderer Renderer) {
+	c.renderer = renderer
+}
 
-		_, err = filePath.Write([]byte(changelog))
-		if err != nil {
-			return fmt.Errorf("failed to write changelog: %w", err)
+// sortEntries returns the entries sorted by scope and description, like conventional-changelog.
+// Entries without scope come first.
+func sortEntries(entries []Entry) []Entry {
+	sorted := slices.Clone(entries)
+
+	slices.SortStableFunc(sorted, func(a, b Entry) int {
+		if scope := strings.Compare(a.Scope, b.Scope); scope != 0 {
+			return scope
 		}
 
-		return nil
-	}
+		return strings.Compare(strings.ToLower(a.text()), strings.ToLower(b.text()))
+	})
+
+	return sorted
+}
 
-	if !bytes.Contains(data, []byte("<!-- INSERT COMMENT -->")) {
-		return ErrMissingPlaceholder
+// text returns the description of the entry, or the raw message if the entry is not a conventional commit.
+func (e Entry) text() string {
+	if e.Description != "" {
+		return e.Description
 	}
 
-	data = bytes.Replace(data, []byte("<!-- INSERT COMMENT -->"), []byte("<!-- INSERT COMMENT -->\n"+c.String()), 1)
+	return e.Message
+}
+
+// formatPersons returns the unique persons as @username, if known, otherwise by name.
+func (c *Changelog) formatPersons(persons []Person) []string {
+	formatted := make([]string, 0, len(persons))
 
-	if _, err = filePath.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("failed to seek file: %w", err)
-	}
+	for _, person := range persons {
+		name := person.Name
 
-	if _, err = filePath.Write(data); err != nil {
-		return fmt.Errorf("failed to write changelog: %w", err)
+		if username, ok := c.usernames[strings.ToLower(person.Email)]; ok {
+			name = "@" + username
+		}
+
+		if !slices.Contains(formatted, name) {
+			formatted = append(formatted, name)
+		}
 	}
 
-	return nil
+	return formatted
 }
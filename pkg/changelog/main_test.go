@@ -3,6 +3,7 @@ package changelog_test
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"testing"
 	"time"
 
@@ -11,10 +12,20 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+const date = "2024-05-06"
+
+// newTestChangelog returns a changelog with a fixed release date.
+func newTestChangelog() *changelog.Changelog {
+	changes := changelog.New()
+	changes.SetDate(time.Date(2024, 5, 6, 12, 0, 0, 0, time.UTC))
+
+	return changes
+}
+
 func TestChangelogEmpty(t *testing.T) {
 	t.Parallel()
 
-	changes := changelog.New()
+	changes := newTestChangelog()
 	assert.Equal(t, 0, changes.Len())
 	assert.Equal(t, "", changes.String())
 }
@@ -22,8 +33,6 @@ func TestChangelogEmpty(t *testing.T) {
 func TestChangelog(t *testing.T) {
 	t.Parallel()
 
-	date := time.Now().Format("2006-01-02")
-
 	for _, tc := range []struct {
 		name              string
 		clFunc            func(changes *changelog.Changelog)
@@ -88,7 +97,7 @@ func TestChangelog(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			changes := changelog.New()
+			changes := newTestChangelog()
 
 			tc.clFunc(changes)
 			changes.SetOldVersion("1.0.0")
@@ -101,10 +110,178 @@ func TestChangelog(t *testing.T) {
 	}
 }
 
+func TestChangelogFirstRelease(t *testing.T) {
+	t.Parallel()
+
+	changes := newTestChangelog()
+	changes.SetRemote("https://github.com/jkroepke/semantic-releaser.git")
+	changes.SetNewVersion("1.0.0")
+	changes.AddFeature("Adding a new feature", "123456")
+
+	expected := fmt.Sprintf(`## 1.0.0 (%s)
+
+### Features
+
+* Adding a new feature ([123456](https://github.com/jkroepke/semantic-releaser/commit/123456))
+
+`, date)
+
+	assert.Equal(t, expected, changes.String())
+}
+
+func TestChangelogAuthors(t *testing.T) {
+	t.Parallel()
+
+	changes := newTestChangelog()
+	changes.SetAuthors(true)
+	changes.SetContributors(true)
+	changes.SetUsernames(map[string]string{"Jane@Example.com": "jane"})
+	changes.SetOldVersion("1.0.0")
+	changes.SetNewVersion("1.1.0")
+
+	changes.Add(changelog.SectionFeatures, changelog.Entry{
+		Hash:      "123456",
+		Message:   "Adding a new feature",
+		Author:    changelog.Person{Name: "Jane Doe", Email: "jane@example.com"},
+		CoAuthors: []changelog.Person{{Name: "Bob", Email: "bob@example.com"}},
+	})
+	changes.Add(changelog.SectionFixes, changelog.Entry{
+		Hash:    "234567",
+		Message: "Fixing a bug",
+		Author:  changelog.Person{Name: "Alice", Email: "alice@example.com"},
+	})
+
+	expected := fmt.Sprintf(`## 1.1.0 (%s)
+
+### Features
+
+* Adding a new feature (123456) by @jane, Bob
+
+### Bug Fixes
+
+* Fixing a bug (234567) by Alice
+
+### Contributors
+
+* Alice
+* Bob
+* @jane
+
+`, date)
+
+	assert.Equal(t, expected, changes.String())
+}
+
+func TestChangelogIssues(t *testing.T) {
+	t.Parallel()
+
+	changes := newTestChangelog()
+	changes.SetRemote("https://github.com/jkroepke/semantic-releaser.git")
+	changes.SetIssuesList(true)
+	changes.AddIssuePattern(changelog.IssuePattern{
+		Regexp: regexp.MustCompile(`\bOPS-\d+\b`),
+		URL:    "https://jira.example.com/browse/$0",
+	})
+	changes.SetOldVersion("1.0.0")
+	changes.SetNewVersion("1.1.0")
+
+	changes.Add(changelog.SectionFeatures, changelog.Entry{
+		Hash:    "123456",
+		Message: "OPS-1234 add a new feature (#12)",
+		Footers: []changelog.Footer{{Key: "Closes", Value: "#45"}, {Key: "Signed-off-by", Value: "Jane <jane@example.com>"}},
+	})
+	changes.Add(changelog.SectionFixes, changelog.Entry{
+		Hash:    "234567",
+		Message: "fix a bug",
+		Footers: []changelog.Footer{{Key: "Refs", Value: "OPS-1234, OPS-99"}},
+	})
+
+	expected := fmt.Sprintf(`## [1.1.0](https://github.com/jkroepke/semantic-releaser/compare/1.0.0...1.1.0) (%s)
+
+### Features
+
+* [OPS-1234](https://jira.example.com/browse/OPS-1234) add a new feature ([#12](https://github.com/jkroepke/semantic-releaser/pull/12)) ([123456](https://github.com/jkroepke/semantic-releaser/commit/123456)), closes [#45](https://github.com/jkroepke/semantic-releaser/issues/45)
+
+### Bug Fixes
+
+* fix a bug ([234567](https://github.com/jkroepke/semantic-releaser/commit/234567)), refs [OPS-1234](https://jira.example.com/browse/OPS-1234), [OPS-99](https://jira.example.com/browse/OPS-99)
+
+### Issues
+
+* [#45](https://github.com/jkroepke/semantic-releaser/issues/45)
+* [OPS-1234](https://jira.example.com/browse/OPS-1234)
+* [OPS-99](https://jira.example.com/browse/OPS-99)
+
+`, date)
+
+	assert.Equal(t, expected, changes.String())
+}
+
+func TestChangelogScopes(t *testing.T) {
+	t.Parallel()
+
+	entries := []changelog.Entry{
+		{Hash: "123456", Message: "feat(api): handle nil", Type: "feat", Scope: "api", Description: "handle nil"},
+		{Hash: "234567", Message: "feat: add x", Type: "feat", Description: "add x"},
+		{Hash: "345678", Message: "feat(api): add endpoint", Type: "feat", Scope: "api", Description: "add endpoint"},
+		{Hash: "456789", Message: "feat(cli): add flag", Type: "feat", Scope: "cli", Description: "add flag"},
+	}
+
+	for _, tc := range []struct {
+		name              string
+		groupByScope      bool
+		sort              bool
+		expectedChangelog string
+	}{
+		{
+			name:              "scopes",
+			expectedChangelog: "* **api:** handle nil (123456)\n* add x (234567)\n* **api:** add endpoint (345678)\n* **cli:** add flag (456789)\n",
+		},
+		{
+			name:              "sorted",
+			sort:              true,
+			expectedChangelog: "* add x (234567)\n* **api:** add endpoint (345678)\n* **api:** handle nil (123456)\n* **cli:** add flag (456789)\n",
+		},
+		{
+			name:              "grouped by scope",
+			groupByScope:      true,
+			sort:              true,
+			expectedChangelog: "* add x (234567)\n* **api:**\n  * add endpoint (345678)\n  * handle nil (123456)\n* **cli:**\n  * add flag (456789)\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			changes := newTestChangelog()
+			changes.SetGroupByScope(tc.groupByScope)
+			changes.SetSort(tc.sort)
+			changes.SetNewVersion("1.1.0")
+
+			for _, entry := range entries {
+				changes.Add(changelog.SectionFeatures, entry)
+			}
+
+			expected := fmt.Sprintf("## 1.1.0 (%s)\n\n### Features\n\n%s\n", date, tc.expectedChangelog)
+			assert.Equal(t, expected, changes.String())
+		})
+	}
+}
+
+func TestChangelogDateFormat(t *testing.T) {
+	t.Parallel()
+
+	changes := newTestChangelog()
+	changes.SetDateFormat("January 2, 2006")
+	changes.SetNewVersion("1.0.1")
+	changes.AddFix("Fixing a bug", "123456")
+
+	assert.Equal(t, "### 1.0.1 (May 6, 2024)\n\n### Bug Fixes\n\n* Fixing a bug (123456)\n\n", changes.String())
+}
+
 func TestChangelogNewFile(t *testing.T) {
 	t.Parallel()
 
-	changes := changelog.New()
+	changes := newTestChangelog()
 	changes.SetOldVersion("1.0.0")
 	changes.SetNewVersion("2.0.0")
 
@@ -124,7 +301,6 @@ func TestChangelogNewFile(t *testing.T) {
 	bytes, err := os.ReadFile(testChangelogFile.Name())
 	require.NoError(t, err)
 
-	date := time.Now().Format("2006-01-02")
 	expected := fmt.Sprintf("## 2.0.0 (%s)\n\n### ⚠ BREAKING CHANGES\n\n* Breaking change (123456)\n\n### Features\n\n* Adding a new feature (123456)\n\n### Bug Fixes\n\n* Fixing a bug (123456)\n\n", date)
 
 	assert.Equal(t, expected, changes.String())
@@ -134,7 +310,7 @@ func TestChangelogNewFile(t *testing.T) {
 func TestChangelogMissingPlaceholder(t *testing.T) {
 	t.Parallel()
 
-	changes := changelog.New()
+	changes := newTestChangelog()
 	changes.SetOldVersion("1.0.0")
 	changes.SetNewVersion("2.0.0")
 
@@ -154,7 +330,7 @@ func TestChangelogMissingPlaceholder(t *testing.T) {
 func TestChangelogExistingFile(t *testing.T) {
 	t.Parallel()
 
-	changes := changelog.New()
+	changes := newTestChangelog()
 	changes.SetOldVersion("1.0.0")
 	changes.SetNewVersion("1.1.0")
 
@@ -171,7 +347,7 @@ func TestChangelogExistingFile(t *testing.T) {
 	testChangelogFile, err = os.OpenFile(testChangelogFile.Name(), os.O_RDWR, 0)
 	require.NoError(t, err)
 
-	changes = changelog.New()
+	changes = newTestChangelog()
 	changes.SetOldVersion("1.1.0")
 	changes.SetNewVersion("1.1.1")
 
@@ -180,7 +356,6 @@ func TestChangelogExistingFile(t *testing.T) {
 	err = changes.WriteTo(testChangelogFile)
 	require.NoError(t, err)
 
-	date := time.Now().Format("2006-01-02")
 	expected := fmt.Sprintf("# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n<!-- INSERT COMMENT -->\n### 1.1.1 (%[1]s)\n\n### Features\n\n* Fixed a bug (123456)\n\n\n## 1.1.0 (%[1]s)\n\n### Features\n\n* Adding a new feature (123456)\n\n", date)
 
 	bytes, err := os.ReadFile(testChangelogFile.Name())
@@ -0,0 +1,91 @@
+package changelog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+const markdownPlaceholder = "<!-- INSERT COMMENT -->"
+
+// Markdown renders the changelog like conventional-changelog.
+type Markdown struct{}
+
+var markdownMarkup = markup{
+	heading: func(level int, text string) string {
+		return strings.Repeat("#", level) + " " + text + "\n\n"
+	},
+	link: func(text, url string) string {
+		return fmt.Sprintf("[%s](%s)", text, url)
+	},
+	bold: func(text string) string {
+		return "**" + text + "**"
+	},
+	bullet: func(level int) string {
+		return strings.Repeat("  ", level-1) + "* "
+	},
+}
+
+func (Markdown) Render(c *Changelog) string {
+	return renderConventional(c, markdownMarkup)
+}
+
+func (r Markdown) Write(c *Changelog, file io.ReadWriteSeeker) error {
+	header := "# Changelog\n\nAll notable changes to this project will be documented in this file.\n\n" + markdownPlaceholder + "\n"
+
+	return insertAtPlaceholder(file, header, markdownPlaceholder, r.Render(c))
+}
+
+func (Markdown) Extract(file []byte, version string) (string, bool) {
+	return extractRelease(file, version)
+}
+
+func (Markdown) FileName() string {
+	return "CHANGELOG.md"
+}
+
+// renderConventional renders the release like conventional-changelog in the given markup.
+// Minor and major releases use a level 2 heading, patch releases a level 3 heading.
+func renderConventional(c *Changelog, m markup) string {
+	sb := &strings.Builder{}
+
+	title := c.newVersion
+	if link := c.getCompareLink(); link != "" {
+		title = m.link(c.newVersion, link)
+	}
+
+	title = fmt.Sprintf("%s (%s)", title, c.getDate())
+
+	if strings.HasSuffix(c.newVersion, ".0") {
+		sb.WriteString(m.heading(2, title))
+	} else {
+		sb.WriteString(m.heading(3, title))
+	}
+
+	for _, section := range []struct {
+		header  string
+		entries []Entry
+	}{
+		{"⚠ BREAKING CHANGES", c.breaking},
+		{"Features", c.features},
+		{"Bug Fixes", c.fixes},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+
+		sb.WriteString(m.heading(3, section.header))
+		c.writeEntries(sb, section.entries, m)
+		sb.WriteString("\n")
+	}
+
+	if c.issuesList {
+		c.writeIssues(sb, m)
+	}
+
+	if c.contributors {
+		c.writeContributors(sb, m)
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,239 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Renderer renders the changelog of a release and inserts it into a changelog file.
+type Renderer interface {
+	// Render returns the changelog of the release.
+	Render(c *Changelog) string
+	// Write inserts the changelog of the release into the changelog file.
+	Write(c *Changelog, file io.ReadWriteSeeker) error
+	// FileName returns the default name of the changelog file.
+	FileName() string
+	// Extract returns the release of the version from the changelog file, as rendered by Render.
+	Extract(file []byte, version string) (string, bool)
+}
+
+const (
+	FormatMarkdown       = "markdown"
+	FormatKeepAChangelog = "keepachangelog"
+	FormatAsciiDoc       = "asciidoc"
+	FormatJSON           = "json"
+)
+
+// NewRenderer returns the renderer of the given format.
+func NewRenderer(format string) (Renderer, error) {
+	switch format {
+	case FormatMarkdown, "":
+		return Markdown{}, nil
+	case FormatKeepAChangelog:
+		return KeepAChangelog{}, nil
+	case FormatAsciiDoc:
+		return AsciiDoc{}, nil
+	case FormatJSON:
+		return JSON{}, nil
+	default:
+		return nil, fmt.Errorf("%q: %w", format, ErrUnknownFormat)
+	}
+}
+
+// markup defines the syntax of a text based changelog format.
+type markup struct {
+	heading func(level int, text string) string
+	link    func(text, url string) string
+	bold    func(text string) string
+	bullet  func(level int) string
+}
+
+// writeEntries writes the entries as list. If enabled, the entries are sorted and grouped by scope.
+func (c *Changelog) writeEntries(sb *strings.Builder, entries []Entry, m markup) {
+	if c.sort {
+		entries = sortEntries(entries)
+	}
+
+	if !c.groupByScope {
+		for _, entry := range entries {
+			sb.WriteString(m.bullet(1))
+			sb.WriteString(c.formatEntry(entry, true, m))
+			sb.WriteString("\n")
+		}
+
+		return
+	}
+
+	var scopes []string
+
+	grouped := map[string][]Entry{}
+
+	// entries without scope first, followed by the entries nested by scope.
+	for _, entry := range entries {
+		if entry.Scope == "" {
+			sb.WriteString(m.bullet(1))
+			sb.WriteString(c.formatEntry(entry, false, m))
+			sb.WriteString("\n")
+
+			continue
+		}
+
+		if _, ok := grouped[entry.Scope]; !ok {
+			scopes = append(scopes, entry.Scope)
+		}
+
+		grouped[entry.Scope] = append(grouped[entry.Scope], entry)
+	}
+
+	for _, scope := range scopes {
+		sb.WriteString(m.bullet(1))
+		sb.WriteString(m.bold(scope + ":"))
+		sb.WriteString("\n")
+
+		for _, entry := range grouped[scope] {
+			sb.WriteString(m.bullet(2))
+			sb.WriteString(c.formatEntry(entry, false, m))
+			sb.WriteString("\n")
+		}
+	}
+}
+
+// formatEntry returns the entry including links, references and authors.
+// If withScope is enabled, the scope is rendered as bold prefix.
+func (c *Changelog) formatEntry(entry Entry, withScope bool, m markup) string {
+	sb := &strings.Builder{}
+
+	message := c.decorateMessage(entry.text(), m)
+
+	if withScope && entry.Description != "" && entry.Scope != "" {
+		message = m.bold(entry.Scope+":") + " " + message
+	}
+
+	sb.WriteString(message)
+
+	if link := c.getCommitLink(entry.Hash); link != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", m.link(entry.Hash, link)))
+	} else {
+		sb.WriteString(fmt.Sprintf(" (%s)", entry.Hash))
+	}
+
+	sb.WriteString(c.footerReferences(entry.Footers, m))
+
+	if c.authors && entry.Author.Name != "" {
+		sb.WriteString(" by ")
+		sb.WriteString(strings.Join(c.formatPersons(append([]Person{entry.Author}, entry.CoAuthors...)), ", "))
+	}
+
+	return sb.String()
+}
+
+// contributorNames returns all unique authors and co-authors of the release, sorted by name.
+func (c *Changelog) contributorNames() []string {
+	var persons []Person
+
+	for _, entries := range [][]Entry{c.breaking, c.features, c.fixes} {
+		for _, entry := range entries {
+			if entry.Author.Name != "" {
+				persons = append(persons, entry.Author)
+			}
+
+			persons = append(persons, entry.CoAuthors...)
+		}
+	}
+
+	contributors := c.formatPersons(persons)
+
+	slices.SortFunc(contributors, func(a, b string) int {
+		return strings.Compare(strings.ToLower(strings.TrimPrefix(a, "@")), strings.ToLower(strings.TrimPrefix(b, "@")))
+	})
+
+	return contributors
+}
+
+// writeContributors writes a section with all unique authors and co-authors.
+func (c *Changelog) writeContributors(sb *strings.Builder, m markup) {
+	contributors := c.contributorNames()
+	if len(contributors) == 0 {
+		return
+	}
+
+	sb.WriteString(m.heading(3, "Contributors"))
+
+	for _, contributor := range contributors {
+		sb.WriteString(m.bullet(1))
+		sb.WriteString(contributor)
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("\n")
+}
+
+// insertAtPlaceholder inserts the release after the placeholder of the changelog file.
+// If the file is empty, the header is written first. The header must contain the placeholder.
+func insertAtPlaceholder(file io.ReadWriteSeeker, header, placeholder, release string) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(data) == 0 {
+		_, err = file.Write([]byte(header + release))
+		if err != nil {
+			return fmt.Errorf("failed to write changelog: %w", err)
+		}
+
+		return nil
+	}
+
+	if !bytes.Contains(data, []byte(placeholder)) {
+		return ErrMissingPlaceholder
+	}
+
+	data = bytes.Replace(data, []byte(placeholder), []byte(placeholder+"\n"+release), 1)
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek file: %w", err)
+	}
+
+	if _, err = file.Write(data); err != nil {
+		return fmt.Errorf("failed to write changelog: %w", err)
+	}
+
+	return nil
+}
+
+// releaseHeading matches the heading of a release in the text based formats, e.g. "## [1.2.3](...) (2024-01-02)",
+// "## [1.2.3] - 2024-01-02" or "=== link:...[1.2.3] (2024-01-02)". The version is captured.
+var releaseHeading = regexp.MustCompile(`^(?:#{2,3}|={2,3}) (?:\[|link:[^\s\[]*\[)?(\d+\.\d+\.\d+[^\s\]]*)`)
+
+// extractRelease returns the lines from the heading of the release up to the heading of the next release.
+// Like the rendered release, the release ends with an empty line.
+func extractRelease(file []byte, version string) (string, bool) {
+	lines := strings.SplitAfter(string(file), "\n")
+	start := -1
+
+	for i, line := range lines {
+		match := releaseHeading.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+
+		if start >= 0 {
+			return strings.TrimRight(strings.Join(lines[start:i], ""), "\n") + "\n\n", true
+		}
+
+		if match[1] == version {
+			start = i
+		}
+	}
+
+	if start < 0 {
+		return "", false
+	}
+
+	return strings.TrimRight(strings.Join(lines[start:], ""), "\n") + "\n\n", true
+}
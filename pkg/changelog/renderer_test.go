@@ -0,0 +1,164 @@
+package changelog_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+//nolint:gochecknoglobals
+var update = flag.Bool("update", false, "update golden files")
+
+// newGoldenChangelog returns a changelog using most of the features.
+func newGoldenChangelog(t *testing.T, format string) *changelog.Changelog {
+	t.Helper()
+
+	renderer, err := changelog.NewRenderer(format)
+	require.NoError(t, err)
+
+	changes := newTestChangelog()
+	changes.SetRenderer(renderer)
+	changes.SetRemote("https://github.com/jkroepke/semantic-releaser.git")
+	changes.SetOldVersion("1.0.0")
+	changes.SetNewVersion("2.0.0")
+	changes.SetAuthors(true)
+	changes.SetContributors(true)
+	changes.SetIssuesList(true)
+	changes.SetSort(true)
+	changes.SetUsernames(map[string]string{"jane@example.com": "jane"})
+	changes.AddIssuePattern(changelog.IssuePattern{Regexp: regexp.MustCompile(`\bOPS-\d+\b`), URL: "https://jira.example.com/browse/$0"})
+
+	jane := changelog.Person{Name: "Jane Doe", Email: "jane@example.com"}
+	bob := changelog.Person{Name: "Bob", Email: "bob@example.com"}
+
+	changes.Add(changelog.SectionBreaking, changelog.Entry{
+		Hash: "1234567", Message: "feat(api)!: remove v1 endpoints", Type: "feat", Scope: "api", Description: "remove v1 endpoints", Author: jane,
+	})
+	changes.Add(changelog.SectionBreaking, changelog.Entry{
+		Hash: "2345678", Message: "fix!: rename flag", Type: "fix", Description: "rename flag", Author: bob,
+	})
+	changes.Add(changelog.SectionFeatures, changelog.Entry{
+		Hash: "3456789", Message: "feat(cli): OPS-12 add flag (#34)", Type: "feat", Scope: "cli", Description: "OPS-12 add flag (#34)",
+		Author: jane, CoAuthors: []changelog.Person{bob},
+	})
+	changes.Add(changelog.SectionFixes, changelog.Entry{
+		Hash: "4567890", Message: "fix: handle nil", Type: "fix", Description: "handle nil", Author: bob,
+		Footers: []changelog.Footer{{Key: "Closes", Value: "#45"}},
+	})
+
+	return changes
+}
+
+func TestRenderers(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{changelog.FormatMarkdown, changelog.FormatKeepAChangelog, changelog.FormatAsciiDoc, changelog.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			changes := newGoldenChangelog(t, format)
+			goldenFile := filepath.Join("testdata", format+".golden")
+
+			if *update {
+				require.NoError(t, os.WriteFile(goldenFile, []byte(changes.String()), 0o600))
+			}
+
+			expected, err := os.ReadFile(goldenFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), changes.String())
+		})
+	}
+}
+
+func TestRendererUnknownFormat(t *testing.T) {
+	t.Parallel()
+
+	_, err := changelog.NewRenderer("rst")
+	require.ErrorIs(t, err, changelog.ErrUnknownFormat)
+}
+
+func TestRendererWrite(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{changelog.FormatMarkdown, changelog.FormatKeepAChangelog, changelog.FormatAsciiDoc, changelog.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			testChangelogFile, err := os.Create(filepath.Join(t.TempDir(), "changelog"))
+			require.NoError(t, err)
+
+			defer testChangelogFile.Close()
+
+			// write the same release twice to check the insertion into existing files.
+			changes := newGoldenChangelog(t, format)
+			require.NoError(t, changes.WriteTo(testChangelogFile))
+
+			_, err = testChangelogFile.Seek(0, 0)
+			require.NoError(t, err)
+			require.NoError(t, changes.WriteTo(testChangelogFile))
+
+			goldenFile := filepath.Join("testdata", format+".file.golden")
+
+			data, err := os.ReadFile(testChangelogFile.Name())
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, os.WriteFile(goldenFile, data, 0o600))
+			}
+
+			expected, err := os.ReadFile(goldenFile)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(expected), string(data))
+		})
+	}
+}
+
+func TestRendererExtract(t *testing.T) {
+	t.Parallel()
+
+	for _, format := range []string{changelog.FormatMarkdown, changelog.FormatKeepAChangelog, changelog.FormatAsciiDoc, changelog.FormatJSON} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			testChangelogFile, err := os.Create(filepath.Join(t.TempDir(), "changelog"))
+			require.NoError(t, err)
+
+			defer testChangelogFile.Close()
+
+			// a patch release followed by a major release, which use different heading levels.
+			patch := newGoldenChangelog(t, format)
+			patch.SetOldVersion("1.0.0")
+			patch.SetNewVersion("1.0.1")
+			require.NoError(t, patch.WriteTo(testChangelogFile))
+
+			_, err = testChangelogFile.Seek(0, 0)
+			require.NoError(t, err)
+
+			major := newGoldenChangelog(t, format)
+			require.NoError(t, major.WriteTo(testChangelogFile))
+
+			data, err := os.ReadFile(testChangelogFile.Name())
+			require.NoError(t, err)
+
+			for _, changes := range []*changelog.Changelog{patch, major} {
+				release, ok := changes.Extract(data)
+				require.True(t, ok)
+				assert.Equal(t, changes.String(), release)
+			}
+
+			missing := newGoldenChangelog(t, format)
+			missing.SetNewVersion("1.0.0")
+
+			_, ok := missing.Extract(data)
+			assert.False(t, ok)
+		})
+	}
+}
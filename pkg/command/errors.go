@@ -0,0 +1,7 @@
+package command
+
+import (
+	"errors"
+)
+
+var ErrEmptyCommand = errors.New("command is empty")
@@ -2,32 +2,211 @@ package command
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jkroepke/semantic-releaser/pkg/mask"
+	"github.com/rs/zerolog"
 )
 
-func Run(command string, dir string) error {
-	var cmd *exec.Cmd
+// defaultWaitDelay is the default time between the interrupt signal and the kill of a canceled command.
+const defaultWaitDelay = 10 * time.Second
+
+// tailLines is the number of output lines included in the error of a failed command.
+const tailLines = 20
+
+type Options struct {
+	// Dir is the working directory of the command.
+	Dir string
+	// Env is appended to the environment of the current process.
+	Env []string
+	// Timeout cancels the command after the duration. Zero means no timeout.
+	Timeout time.Duration
+	// WaitDelay is the grace period between the interrupt and the kill of the process group of a canceled command.
+	// Defaults to 10 seconds.
+	WaitDelay time.Duration
+	// Logger receives the output of the command line by line.
+	Logger zerolog.Logger
+	// Masker redacts secrets from the output and the error of the command.
+	Masker *mask.Masker
+	// Shell runs string commands, the command is appended as last argument, e.g. bash -euo pipefail -c.
+	// Defaults to sh -c, or cmd /C on windows.
+	Shell []string
+}
+
+// Run runs the command in a shell. The output is streamed line by line to the logger.
+// If the context is canceled or the timeout is exceeded, the command is interrupted and
+// killed after a grace period.
+func Run(ctx context.Context, command string, opts Options) error {
+	shell := opts.Shell
+
+	if len(shell) == 0 {
+		switch runtime.GOOS {
+		case "windows":
+			shell = []string{"cmd", "/C"}
+		default:
+			shell = []string{"sh", "-c"}
+		}
+	}
+
+	return run(ctx, append(slices.Clone(shell), command), command, opts)
+}
+
+// RunArgs runs the command without a shell. args[0] is the program, the remaining elements are passed
+// as arguments as they are. Otherwise, it behaves like Run.
+func RunArgs(ctx context.Context, args []string, opts Options) error {
+	if len(args) == 0 {
+		return ErrEmptyCommand
+	}
+
+	return run(ctx, args, strings.Join(args, " "), opts)
+}
+
+// run runs the args. The command is the string representation of the command, used in errors.
+func run(ctx context.Context, args []string, command string, opts Options) error {
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
 
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("cmd", "/C", command)
-	default:
-		cmd = exec.Command("sh", "-c", command)
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
 	}
 
-	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...) //nolint:gosec // hook commands are defined by the user
 
-	cmd.Env = os.Environ()
-	cmd.Dir = dir
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
+	cmd.Env = append(os.Environ(), opts.Env...)
+	cmd.Dir = opts.Dir
+	waitDelay := opts.WaitDelay
+	if waitDelay <= 0 {
+		waitDelay = defaultWaitDelay
+	}
+
+	cmd.WaitDelay = waitDelay
+	cmd.Cancel = func() error {
+		// after the wait delay, exec kills only the command itself. Children ignoring the interrupt are killed as well.
+		time.AfterFunc(waitDelay, func() { _ = kill(cmd) })
+
+		return interrupt(cmd)
+	}
+
+	setProcessGroup(cmd)
+
+	// stdout and stderr are copied concurrently, the lines are logged one at a time.
+	var mu sync.Mutex
+
+	stdout := newLineWriter(&mu, opts.Logger.With().Str("stream", "stdout").Logger(), opts.Masker, tailLines)
+	stderr := newLineWriter(&mu, opts.Logger.With().Str("stream", "stderr").Logger(), opts.Masker, tailLines)
+
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+
+	stdout.Flush()
+	stderr.Flush()
+
+	if err == nil {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return fmt.Errorf("failed to run command: %w", err)
+	}
 
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("failed to package project: %w\n\nSTDOUT:\n\n%s\n\nSTDERR:\n\n%s", err, stdout.String(), stderr.String())
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = errors.Join(err, ctxErr)
 	}
 
-	return nil
+	return &Error{
+		Command:  opts.Masker.Mask(command),
+		ExitCode: exitErr.ExitCode(),
+		Stdout:   strings.Join(stdout.tail, "\n"),
+		Stderr:   strings.Join(stderr.tail, "\n"),
+		err:      err,
+	}
+}
+
+// Error is returned, if the command exited with an error or was canceled.
+type Error struct {
+	// Command is the masked command.
+	Command string
+	// ExitCode is the exit code of the command, or -1 if the command was killed by a signal.
+	ExitCode int
+	// Stdout and Stderr contain the last masked lines of the output.
+	Stdout string
+	Stderr string
+	err    error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("failed to run command %q: %s\n\nSTDOUT:\n\n%s\n\nSTDERR:\n\n%s", e.Command, e.err, e.Stdout, e.Stderr)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// lineWriter logs each written line, masked by the masker. The last lines are kept, if tailLines is greater than zero.
+type lineWriter struct {
+	mu        *sync.Mutex
+	logger    zerolog.Logger
+	masker    *mask.Masker
+	buf       []byte
+	tail      []string
+	tailLines int
+}
+
+func newLineWriter(mu *sync.Mutex, logger zerolog.Logger, masker *mask.Masker, tailLines int) *lineWriter {
+	return &lineWriter{mu: mu, logger: logger, masker: masker, tailLines: tailLines}
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.log(string(bytes.TrimRight(w.buf[:i], "\r")))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// Flush logs the last line, if it is not terminated by a newline.
+func (w *lineWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.buf) > 0 {
+		w.log(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *lineWriter) log(line string) {
+	line = w.masker.Mask(line)
+
+	w.logger.Info().Msg(line)
+
+	if w.tailLines > 0 {
+		w.tail = append(w.tail, line)
+		if len(w.tail) > w.tailLines {
+			w.tail = w.tail[1:]
+		}
+	}
 }
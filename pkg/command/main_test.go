@@ -0,0 +1,151 @@
+//go:build !windows
+
+package command_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/jkroepke/semantic-releaser/pkg/command"
+	"github.com/jkroepke/semantic-releaser/pkg/mask"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+
+	err := command.Run(context.Background(), `echo "$GREETING"; printf 'no newline' >&2`, command.Options{
+		Dir:    t.TempDir(),
+		Env:    []string{"GREETING=hello"},
+		Logger: zerolog.New(&logs),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		`{"level":"info","stream":"stdout","message":"hello"}`+"\n"+
+			`{"level":"info","stream":"stderr","message":"no newline"}`+"\n",
+		logs.String(),
+	)
+}
+
+func TestRunFailure(t *testing.T) {
+	t.Parallel()
+
+	err := command.Run(context.Background(), "echo chart invalid; echo first >&2; echo last >&2; exit 3", command.Options{Logger: zerolog.Nop()})
+	require.ErrorContains(t, err, "exit status 3")
+	require.ErrorContains(t, err, "STDOUT:\n\nchart invalid\n\nSTDERR:\n\nfirst\nlast")
+}
+
+func TestRunKillProcessGroup(t *testing.T) {
+	t.Parallel()
+
+	pidFile := filepath.Join(t.TempDir(), "pid")
+
+	// the child ignores SIGTERM and does not hold the output pipes.
+	err := command.Run(context.Background(), `trap "" TERM; sleep 30 >/dev/null 2>&1 & echo $! > `+pidFile+`; wait`, command.Options{
+		Timeout:   100 * time.Millisecond,
+		WaitDelay: 200 * time.Millisecond,
+		Logger:    zerolog.Nop(),
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	content, err := os.ReadFile(pidFile)
+	require.NoError(t, err)
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+	require.NoError(t, err)
+
+	// the killed child is reaped by init, signal 0 fails afterwards.
+	assert.Eventually(t, func() bool {
+		return syscall.Kill(pid, 0) != nil
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func TestRunTimeout(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	err := command.Run(context.Background(), "sleep 10; echo done", command.Options{
+		Timeout: 100 * time.Millisecond,
+		Logger:  zerolog.Nop(),
+	})
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestRunCancel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	err := command.Run(ctx, "sleep 10", command.Options{Logger: zerolog.Nop()})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRunError(t *testing.T) {
+	t.Parallel()
+
+	err := command.Run(context.Background(), "echo registry unavailable; exit 42", command.Options{Logger: zerolog.Nop()})
+
+	var commandErr *command.Error
+	require.ErrorAs(t, err, &commandErr)
+	assert.Equal(t, 42, commandErr.ExitCode)
+	assert.Equal(t, "registry unavailable", commandErr.Stdout)
+	assert.Empty(t, commandErr.Stderr)
+}
+
+func TestRunMask(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+
+	err := command.Run(context.Background(), "echo login s3cr3t; echo denied s3cr3t >&2; exit 1", command.Options{
+		Logger: zerolog.New(&logs),
+		Masker: mask.New([]string{"s3cr3t"}),
+	})
+	require.Error(t, err)
+
+	assert.NotContains(t, err.Error(), "s3cr3t")
+	assert.NotContains(t, logs.String(), "s3cr3t")
+	assert.Contains(t, err.Error(), "denied ***")
+	assert.Contains(t, logs.String(), "login ***")
+}
+
+func TestRunArgs(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+
+	err := command.RunArgs(context.Background(), []string{"echo", "it's $HOME; exit 1"}, command.Options{Logger: zerolog.New(&logs)})
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"level":"info","stream":"stdout","message":"it's $HOME; exit 1"}`+"\n", logs.String())
+
+	require.ErrorIs(t, command.RunArgs(context.Background(), nil, command.Options{}), command.ErrEmptyCommand)
+}
+
+func TestRunShell(t *testing.T) {
+	t.Parallel()
+
+	err := command.Run(context.Background(), "false | true", command.Options{Logger: zerolog.Nop()})
+	require.NoError(t, err)
+
+	err = command.Run(context.Background(), "false | true", command.Options{
+		Logger: zerolog.Nop(),
+		Shell:  []string{"bash", "-euo", "pipefail", "-c"},
+	})
+	require.ErrorContains(t, err, "exit status 1")
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package command
+
+import (
+	"os/exec"
+)
+
+func setProcessGroup(_ *exec.Cmd) {}
+
+// interrupt kills the command, since windows does not support signals.
+func interrupt(cmd *exec.Cmd) error {
+	return cmd.Process.Kill() //nolint:wrapcheck
+}
+
+// kill kills the command.
+func kill(cmd *exec.Cmd) error {
+	return cmd.Process.Kill() //nolint:wrapcheck
+}
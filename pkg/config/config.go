@@ -4,28 +4,83 @@ import (
 	"flag"
 	"fmt"
 	"io"
+	"os"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
 )
 
 type Config struct {
-	ProjectsDir       string
-	ConfigFilePath    string
-	GitTagPattern     string
-	GenerateChangelog bool
-	GitWriteBack      bool
+	// Command is the subcommand, e.g. CommandPublish. Empty for the release.
+	Command string
+	// PublishTag is the existing tag, which is published by CommandPublish.
+	PublishTag          string
+	ProjectsDir         string
+	ConfigFilePath      string
+	GitTagPattern       string
+	GitTagVersionPrefix string
+	// GitTagLegacyPatterns are only used to read the current version, e.g. after a migration of GitTagPattern.
+	GitTagLegacyPatterns []string
+	GenerateChangelog    bool
+	GitWriteBack         bool
+	// GitPushAttempts is the maximum number of push attempts. If the remote branch moved,
+	// the release commit is reapplied onto the remote branch before the next attempt.
+	GitPushAttempts int
+	ReleaseAs       map[string]*semver.Version
+	// MergeCommits defines how merge commits are handled. See MergeCommitsInclude and MergeCommitsFirstParent.
+	MergeCommits string
+	// Concurrency is the maximum number of projects released in parallel.
+	Concurrency int
+	// MaskEnv are environment variables, whose values are masked in the output and errors of hook commands.
+	MaskEnv []string
+	// ParseSquashBody enables parsing of conventional commit entries from the commit body, e.g. of squash merges.
+	ParseSquashBody bool
 }
 
+// CommandPublish runs only the publishNewVersion hook for an existing tag.
+const CommandPublish = "publish"
+
+const (
+	// MergeCommitsInclude skips merge commits and includes the commits of merged branches.
+	MergeCommitsInclude = "include"
+	// MergeCommitsFirstParent follows only the first parent. The body of merge commits, e.g. the title of
+	// the pull request, is parsed as conventional commit message.
+	MergeCommitsFirstParent = "first-parent"
+)
+
 func New() *Config {
 	return &Config{
+		Concurrency:       1,
 		ConfigFilePath:    ".releaser.yaml",
 		GenerateChangelog: true,
+		GitPushAttempts:   3,
 		GitTagPattern:     "{project}/{version}",
+		MergeCommits:      MergeCommitsInclude,
 		ProjectsDir:       "charts",
+		ReleaseAs:         map[string]*semver.Version{},
 	}
 }
 
 func (c *Config) Load(args []string, logWriter io.Writer) error {
-	flagSet := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	name := args[0]
+
+	if len(args) > 1 && args[1] == CommandPublish {
+		c.Command = CommandPublish
+		name += " " + CommandPublish
+		args = args[1:]
+	}
+
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
 	flagSet.SetOutput(logWriter)
+
+	if c.Command == CommandPublish {
+		flagSet.StringVar(&c.PublishTag,
+			"tag",
+			"",
+			"Existing tag to publish, e.g. project/1.2.3. The tag is checked out and only the publishNewVersion command is run.",
+		)
+	}
+
 	flagSet.StringVar(&c.ProjectsDir,
 		"projects-dir",
 		lookupEnvOrString("PROJECTS_DIR", c.ProjectsDir),
@@ -41,7 +96,25 @@ func (c *Config) Load(args []string, logWriter io.Writer) error {
 	flagSet.StringVar(&c.GitTagPattern,
 		"git-tag-pattern",
 		lookupEnvOrString("GIT_TAG_PATTERN", c.GitTagPattern),
-		"Pattern for git tags. Use {project} and {version} as placeholders.",
+		"Pattern for git tags. Placeholders: {project}, {version}, {major}, {minor}, {patch}, {prerelease}, {sha} and {shortSha}.",
+	)
+
+	flagSet.StringVar(&c.GitTagVersionPrefix,
+		"git-tag-version-prefix",
+		lookupEnvOrString("GIT_TAG_VERSION_PREFIX", c.GitTagVersionPrefix),
+		"Prefix for the {version} placeholder in git tags, e.g. 'v'. The prefix is stripped while parsing tags.",
+	)
+
+	c.GitTagLegacyPatterns = lookupEnvOrStrings("GIT_TAG_LEGACY_PATTERNS", c.GitTagLegacyPatterns)
+
+	flagSet.Func("git-tag-legacy-pattern",
+		"Legacy pattern for git tags. Only used to detect the current version, new tags are created with git-tag-pattern. "+
+			"The git-tag-version-prefix is not applied. Can be specified multiple times.",
+		func(pattern string) error {
+			c.GitTagLegacyPatterns = append(c.GitTagLegacyPatterns, pattern)
+
+			return nil
+		},
 	)
 
 	flagSet.BoolVar(&c.GitWriteBack,
@@ -50,15 +123,105 @@ func (c *Config) Load(args []string, logWriter io.Writer) error {
 		"If enabled, changes on local files will be commit back to git repository.",
 	)
 
+	flagSet.IntVar(&c.GitPushAttempts,
+		"git-push-attempts",
+		lookupEnvOrInt("GIT_PUSH_ATTEMPTS", c.GitPushAttempts),
+		"Maximum number of push attempts. If the remote branch moved, the release commit is reapplied onto the remote branch, "+
+			"unless the remote branch contains new commits of the project.",
+	)
+
+	flagSet.IntVar(&c.Concurrency,
+		"concurrency",
+		lookupEnvOrInt("CONCURRENCY", c.Concurrency),
+		"Maximum number of projects released in parallel. The projects are started in order of their names. "+
+			"The release commits and pushes are serialized in the same order.",
+	)
+
 	flagSet.BoolVar(&c.GenerateChangelog,
 		"generate-changelog",
 		lookupEnvOrBool("GENERATE_CHANGELOG", c.GenerateChangelog),
 		"If enabled, changes on local files will be commit back to git repository.",
 	)
 
+	flagSet.StringVar(&c.MergeCommits,
+		"merge-commits",
+		lookupEnvOrString("MERGE_COMMITS", c.MergeCommits),
+		"Handling of merge commits. 'include' skips merge commits and includes the commits of merged branches. "+
+			"'first-parent' follows only the first parent and parses the body of merge commits as conventional commit message.",
+	)
+
+	flagSet.BoolVar(&c.ParseSquashBody,
+		"parse-squash-body",
+		lookupEnvOrBool("PARSE_SQUASH_BODY", c.ParseSquashBody),
+		"If enabled, list entries like '* feat: message' in the commit body are parsed as additional conventional commits.",
+	)
+
+	c.MaskEnv = lookupEnvOrStrings("MASK_ENV", c.MaskEnv)
+
+	flagSet.Func("mask-env",
+		"Environment variable, whose value is masked in the output and errors of hook commands. "+
+			"Known token formats are always masked. Can be specified multiple times.",
+		func(name string) error {
+			c.MaskEnv = append(c.MaskEnv, name)
+
+			return nil
+		},
+	)
+
+	flagSet.Func("release-as",
+		"Force the next version of a project. Format: project=version. An unknown project fails the release. "+
+			"Can be specified multiple times.",
+		c.parseReleaseAs,
+	)
+
+	if val, ok := os.LookupEnv("RELEASE_AS"); ok {
+		for _, releaseAs := range strings.Split(val, ",") {
+			if strings.TrimSpace(releaseAs) == "" {
+				continue
+			}
+
+			if err := c.parseReleaseAs(releaseAs); err != nil {
+				return fmt.Errorf("error parsing RELEASE_AS: %w", err)
+			}
+		}
+	}
+
 	if err := flagSet.Parse(args[1:]); err != nil {
 		return fmt.Errorf("error parsing cli args: %w", err)
 	}
 
+	if c.Command == CommandPublish && c.PublishTag == "" {
+		return ErrMissingPublishTag
+	}
+
+	if c.GitPushAttempts < 1 {
+		return fmt.Errorf("%d: %w", c.GitPushAttempts, ErrInvalidGitPushAttempts)
+	}
+
+	if c.Concurrency < 1 {
+		return fmt.Errorf("%d: %w", c.Concurrency, ErrInvalidConcurrency)
+	}
+
+	if c.MergeCommits != MergeCommitsInclude && c.MergeCommits != MergeCommitsFirstParent {
+		return fmt.Errorf("%q: %w", c.MergeCommits, ErrInvalidMergeCommits)
+	}
+
+	return nil
+}
+
+// parseReleaseAs parses a project=version pair and stores it in ReleaseAs.
+func (c *Config) parseReleaseAs(value string) error {
+	projectName, version, ok := strings.Cut(strings.TrimSpace(value), "=")
+	if !ok || projectName == "" {
+		return fmt.Errorf("%q: %w", value, ErrInvalidReleaseAs)
+	}
+
+	semverVersion, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Errorf("%q: %w", value, err)
+	}
+
+	c.ReleaseAs[projectName] = semverVersion
+
 	return nil
 }
@@ -0,0 +1,126 @@
+package config_test
+
+import (
+	"io"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPublish(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name    string
+		args    []string
+		command string
+		tag     string
+		err     error
+	}{
+		{name: "release", args: []string{"semrel", "--projects-dir", "."}},
+		{name: "publish", args: []string{"semrel", "publish", "--tag", "app/1.2.3"}, command: config.CommandPublish, tag: "app/1.2.3"},
+		{name: "publish without tag", args: []string{"semrel", "publish"}, command: config.CommandPublish, err: config.ErrMissingPublishTag},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.New()
+
+			err := conf.Load(tc.args, io.Discard)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			assert.Equal(t, tc.command, conf.Command)
+			assert.Equal(t, tc.tag, conf.PublishTag)
+		})
+	}
+}
+
+func TestLoadTagOnlyForPublish(t *testing.T) {
+	t.Parallel()
+
+	require.ErrorContains(t, config.New().Load([]string{"semrel", "--tag", "app/1.2.3"}, io.Discard), "flag provided but not defined: -tag")
+}
+
+func TestLoadReleaseAs(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		args     []string
+		expected map[string]string
+		err      error
+	}{
+		{"none", nil, map[string]string{}, nil},
+		{"single", []string{"--release-as", "app=2.0.0"}, map[string]string{"app": "2.0.0"}, nil},
+		{"multiple", []string{"--release-as", "app=2.0.0", "--release-as", "lib=v1.0.0-rc.1"}, map[string]string{"app": "2.0.0", "lib": "1.0.0-rc.1"}, nil},
+		{"last wins", []string{"--release-as", "app=2.0.0", "--release-as", "app=3.0.0"}, map[string]string{"app": "3.0.0"}, nil},
+		{"missing version", []string{"--release-as", "app"}, nil, config.ErrInvalidReleaseAs},
+		{"missing project", []string{"--release-as", "=2.0.0"}, nil, config.ErrInvalidReleaseAs},
+		{"invalid version", []string{"--release-as", "app=next"}, nil, semver.ErrInvalidSemVer},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := config.New()
+
+			err := conf.Load(append([]string{"semrel"}, tc.args...), io.Discard)
+			if tc.err != nil {
+				// the flag package does not wrap the errors of flag functions.
+				require.ErrorContains(t, err, tc.err.Error())
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			releaseAs := map[string]string{}
+			for name, version := range conf.ReleaseAs {
+				releaseAs[name] = version.String()
+			}
+
+			assert.Equal(t, tc.expected, releaseAs)
+		})
+	}
+}
+
+//nolint:paralleltest // uses t.Setenv
+func TestLoadReleaseAsEnv(t *testing.T) {
+	t.Setenv("RELEASE_AS", "app=2.0.0, ,lib=1.1.0")
+
+	conf := config.New()
+	require.NoError(t, conf.Load([]string{"semrel", "--release-as", "app=3.0.0"}, io.Discard))
+
+	// the flag overrides the environment variable.
+	assert.Equal(t, "3.0.0", conf.ReleaseAs["app"].String())
+	assert.Equal(t, "1.1.0", conf.ReleaseAs["lib"].String())
+
+	t.Setenv("RELEASE_AS", "app")
+	require.ErrorIs(t, config.New().Load([]string{"semrel"}, io.Discard), config.ErrInvalidReleaseAs)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		err  error
+	}{
+		{"concurrency", []string{"--concurrency", "0"}, config.ErrInvalidConcurrency},
+		{"git push attempts", []string{"--git-push-attempts", "0"}, config.ErrInvalidGitPushAttempts},
+		{"merge commits", []string{"--merge-commits", "squash"}, config.ErrInvalidMergeCommits},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.ErrorIs(t, config.New().Load(append([]string{"semrel"}, tc.args...), io.Discard), tc.err)
+		})
+	}
+}
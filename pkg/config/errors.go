@@ -0,0 +1,11 @@
+package config
+
+import "errors"
+
+var (
+	ErrInvalidReleaseAs       = errors.New("invalid release-as value, expected project=version")
+	ErrMissingPublishTag      = errors.New("publish requires the flag --tag")
+	ErrInvalidConcurrency     = errors.New("invalid concurrency value, expected at least 1")
+	ErrInvalidGitPushAttempts = errors.New("invalid git-push-attempts value, expected at least 1")
+	ErrInvalidMergeCommits    = errors.New("invalid merge-commits value, expected include or first-parent")
+)
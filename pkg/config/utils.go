@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 // lookupEnvOrString returns the value of the environment variable named by the key,
@@ -29,3 +30,37 @@ func lookupEnvOrBool(key string, defaultVal bool) bool {
 
 	return defaultVal
 }
+
+// lookupEnvOrInt returns the value of the environment variable named by the key,
+// or the default value if the variable is not set.
+func lookupEnvOrInt(key string, defaultVal int) int {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultVal
+	}
+
+	if parseInt, err := strconv.Atoi(val); err == nil {
+		return parseInt
+	}
+
+	return defaultVal
+}
+
+// lookupEnvOrStrings returns the comma separated values of the environment variable named by the key,
+// or the default values if the variable is not set. Empty values are skipped.
+func lookupEnvOrStrings(key string, defaultVal []string) []string {
+	val, ok := os.LookupEnv(key)
+	if !ok {
+		return defaultVal
+	}
+
+	var values []string
+
+	for _, value := range strings.Split(val, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+
+	return values
+}
@@ -0,0 +1,284 @@
+package history
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Range describes the commits of interest for a single project.
+type Range struct {
+	// Path is the directory of the project, relative to the repository root.
+	// Only commits changing files inside this directory are collected.
+	Path string
+	// Stop is the commit of the last release. Commits reachable from Stop are excluded,
+	// even if Stop itself did not change files inside Path.
+	// If zero, the whole history is collected.
+	Stop plumbing.Hash
+}
+
+// Options configures the history walk.
+type Options struct {
+	// FirstParent follows only the first parent of merge commits, like git log --first-parent.
+	// Commits of merged branches are not collected.
+	FirstParent bool
+	// Start is the commit to walk from. If zero, the walk starts at HEAD.
+	Start plumbing.Hash
+}
+
+// node is a commit in the history walk.
+type node struct {
+	commit *object.Commit
+	// head reports whether the commit is reachable from HEAD.
+	head bool
+	// excluded reports per range whether the commit is reachable from the stop commit of the range.
+	excluded []bool
+	// queued reports whether the node is in the queue.
+	queued bool
+	// visited reports whether the node has been popped at least once.
+	visited bool
+}
+
+// queue is a priority queue of nodes, newest committer time first.
+type queue []*node
+
+func (q queue) Len() int { return len(q) }
+
+func (q queue) Less(i, j int) bool {
+	return q[i].commit.Committer.When.After(q[j].commit.Committer.When)
+}
+
+func (q queue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *queue) Push(x any) { *q = append(*q, x.(*node)) } //nolint:forcetypeassert
+
+func (q *queue) Pop() any {
+	old := *q
+	n := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return n
+}
+
+// Collect walks the history from HEAD, or the start commit, once and returns the commits of each range, newest first.
+// A commit belongs to a range, if it is reachable from HEAD but not from the stop commit of the range
+// and if it changes files inside the path of the range.
+// The changed paths of each commit are computed only once and dispatched to all ranges.
+//
+// The walk is ordered by committer time. Like git, the walk assumes that commits are not older than their parents.
+// Commits with the same committer time may be popped before their children. Such commits are queued again,
+// if a child changes their reachability, and the ranges are only assigned once the walk is done.
+func Collect(repo *git.Repository, ranges []Range, opts Options) ([][]*object.Commit, error) {
+	commits := make([][]*object.Commit, len(ranges))
+
+	if len(ranges) == 0 {
+		return commits, nil
+	}
+
+	start := opts.Start
+
+	if start.IsZero() {
+		head, err := repo.Head()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get HEAD: %w", err)
+		}
+
+		start = head.Hash()
+	}
+
+	nodes := map[plumbing.Hash]*node{}
+	pending := &queue{}
+
+	// visited holds the popped nodes in walk order, newest first.
+	var visited []*node
+
+	// enqueue adds the commit to the queue or merges the reachability into the known node.
+	// A popped node is queued again, if its reachability changed, to pass the change on to its parents.
+	enqueue := func(hash plumbing.Hash, head bool, excluded []bool) error {
+		if n, ok := nodes[hash]; ok {
+			changed := head && !n.head
+			n.head = n.head || head
+
+			for i := range excluded {
+				changed = changed || (excluded[i] && !n.excluded[i])
+				n.excluded[i] = n.excluded[i] || excluded[i]
+			}
+
+			if changed && !n.queued {
+				n.queued = true
+				heap.Push(pending, n)
+			}
+
+			return nil
+		}
+
+		commit, err := repo.CommitObject(hash)
+		if err != nil {
+			return fmt.Errorf("failed to get commit %s: %w", hash, err)
+		}
+
+		n := &node{commit: commit, head: head, excluded: slices.Clone(excluded), queued: true}
+		nodes[hash] = n
+		heap.Push(pending, n)
+
+		return nil
+	}
+
+	if err := enqueue(start, true, make([]bool, len(ranges))); err != nil {
+		return nil, err
+	}
+
+	for i, r := range ranges {
+		if r.Stop.IsZero() {
+			continue
+		}
+
+		excluded := make([]bool, len(ranges))
+		excluded[i] = true
+
+		if err := enqueue(r.Stop, false, excluded); err != nil {
+			return nil, err
+		}
+	}
+
+	var oldest time.Time
+
+	for pending.Len() > 0 && !finished(*pending, oldest) {
+		n := heap.Pop(pending).(*node) //nolint:forcetypeassert
+		n.queued = false
+
+		if oldest.IsZero() || n.commit.Committer.When.Before(oldest) {
+			oldest = n.commit.Committer.When
+		}
+
+		if !n.visited {
+			n.visited = true
+			visited = append(visited, n)
+		}
+
+		for i, parent := range n.commit.ParentHashes {
+			// merged branches are still walked to detect the commits reachable from the stop commits.
+			if err := enqueue(parent, n.head && (i == 0 || !opts.FirstParent), n.excluded); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, n := range visited {
+		if !n.head {
+			continue
+		}
+
+		if err := dispatch(n, ranges, commits); err != nil {
+			return nil, err
+		}
+	}
+
+	return commits, nil
+}
+
+// dispatch adds the commit to all ranges which do not exclude the commit and whose path is changed by the commit.
+func dispatch(n *node, ranges []Range, commits [][]*object.Commit) error {
+	var paths []string
+
+	for i, r := range ranges {
+		if n.excluded[i] {
+			continue
+		}
+
+		if paths == nil {
+			changedPaths, err := ChangedPaths(n.commit)
+			if err != nil {
+				return err
+			}
+
+			paths = changedPaths
+		}
+
+		if containsPath(paths, r.Path) {
+			commits[i] = append(commits[i], n.commit)
+		}
+	}
+
+	return nil
+}
+
+// finished reports whether all pending commits are irrelevant for all ranges
+// and can not change the reachability of a visited commit anymore.
+// A pending commit as old as the oldest visited commit may still be a child of a visited commit.
+func finished(pending queue, oldest time.Time) bool {
+	for _, n := range pending {
+		if n.head && slices.Contains(n.excluded, false) {
+			return false
+		}
+
+		if !oldest.IsZero() && !n.commit.Committer.When.Before(oldest) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ChangedPaths returns the paths changed by the commit compared to its first parent.
+func ChangedPaths(commit *object.Commit) ([]string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tree of commit %s: %w", commit.Hash, err)
+	}
+
+	var parentTree *object.Tree
+
+	if commit.NumParents() > 0 {
+		parent, err := commit.Parent(0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parent of commit %s: %w", commit.Hash, err)
+		}
+
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, fmt.Errorf("failed to get tree of commit %s: %w", parent.Hash, err)
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff commit %s: %w", commit.Hash, err)
+	}
+
+	paths := make([]string, 0, len(changes))
+
+	for _, change := range changes {
+		if change.From.Name != "" {
+			paths = append(paths, change.From.Name)
+		}
+
+		if change.To.Name != "" && change.To.Name != change.From.Name {
+			paths = append(paths, change.To.Name)
+		}
+	}
+
+	return paths, nil
+}
+
+// containsPath reports whether any of the paths is inside the directory dir.
+func containsPath(paths []string, dir string) bool {
+	dir = strings.TrimSuffix(dir, "/")
+
+	if dir == "" || dir == "." {
+		return len(paths) > 0
+	}
+
+	for _, path := range paths {
+		if path == dir || strings.HasPrefix(path, dir+"/") {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,305 @@
+package history_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jkroepke/semantic-releaser/pkg/history"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newSyntheticRepository creates a repository with commits spread round-robin over the projects.
+// The commit at tagAt, if greater than 0, is returned as tag commit.
+func newSyntheticRepository(tb testing.TB, projects, commits, tagAt int) (*git.Repository, plumbing.Hash) {
+	tb.Helper()
+
+	fs := memfs.New()
+
+	repo, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(tb, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(tb, err)
+
+	var tagCommit plumbing.Hash
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for i := range commits {
+		path := fmt.Sprintf("charts/project-%d/file.txt", i%projects)
+
+		file, err := fs.Create(path)
+		require.NoError(tb, err)
+
+		_, err = fmt.Fprintf(file, "commit %d\n", i)
+		require.NoError(tb, err)
+		require.NoError(tb, file.Close())
+
+		_, err = worktree.Add(path)
+		require.NoError(tb, err)
+
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: date.Add(time.Duration(i) * time.Minute)}
+
+		hash, err := worktree.Commit(fmt.Sprintf("feat: commit %d", i), &git.CommitOptions{Author: signature, Committer: signature})
+		require.NoError(tb, err)
+
+		if i == tagAt {
+			tagCommit = hash
+		}
+	}
+
+	return repo, tagCommit
+}
+
+// logPerPath collects the commits like a path filtered log per project.
+func logPerPath(tb testing.TB, repo *git.Repository, ranges []history.Range) [][]*object.Commit {
+	tb.Helper()
+
+	commits := make([][]*object.Commit, len(ranges))
+
+	for i, r := range ranges {
+		commitIter, err := repo.Log(&git.LogOptions{
+			Order: git.LogOrderCommitterTime,
+			PathFilter: func(s string) bool {
+				return strings.HasPrefix(s, r.Path+"/")
+			},
+		})
+		require.NoError(tb, err)
+
+		for commit, err := commitIter.Next(); err == nil; commit, err = commitIter.Next() {
+			if commit.Hash == r.Stop {
+				break
+			}
+
+			commits[i] = append(commits[i], commit)
+		}
+	}
+
+	return commits
+}
+
+func TestCollect(t *testing.T) {
+	t.Parallel()
+
+	repo, tagCommit := newSyntheticRepository(t, 3, 30, 21)
+
+	ranges := []history.Range{
+		{Path: "charts/project-0", Stop: tagCommit},
+		{Path: "charts/project-1"},
+		{Path: "charts/project-2"},
+		{Path: "charts/project"},
+	}
+
+	commits, err := history.Collect(repo, ranges, history.Options{})
+	require.NoError(t, err)
+
+	require.Len(t, commits, 4)
+	assert.Len(t, commits[0], 2)
+	assert.Len(t, commits[1], 10)
+	assert.Len(t, commits[2], 10)
+	assert.Empty(t, commits[3])
+
+	assert.Equal(t, "feat: commit 27", commits[0][0].Message)
+	assert.Equal(t, "feat: commit 24", commits[0][1].Message)
+
+	assert.Equal(t, logPerPath(t, repo, ranges), commits)
+}
+
+func TestCollectStopOutsidePath(t *testing.T) {
+	t.Parallel()
+
+	// the tag commit changes project-1 only.
+	repo, tagCommit := newSyntheticRepository(t, 3, 30, 22)
+
+	commits, err := history.Collect(repo, []history.Range{{Path: "charts/project-0", Stop: tagCommit}}, history.Options{})
+	require.NoError(t, err)
+
+	require.Len(t, commits, 1)
+	require.Len(t, commits[0], 2)
+	assert.Equal(t, "feat: commit 27", commits[0][0].Message)
+	assert.Equal(t, "feat: commit 24", commits[0][1].Message)
+}
+
+func TestCollectStart(t *testing.T) {
+	t.Parallel()
+
+	// the start commit changes project-0.
+	repo, startCommit := newSyntheticRepository(t, 3, 30, 21)
+
+	commits, err := history.Collect(repo, []history.Range{{Path: "charts/project-0"}}, history.Options{Start: startCommit})
+	require.NoError(t, err)
+
+	require.Len(t, commits, 1)
+	require.Len(t, commits[0], 8)
+	assert.Equal(t, "feat: commit 21", commits[0][0].Message)
+}
+
+func TestCollectMerge(t *testing.T) {
+	t.Parallel()
+
+	fs := memfs.New()
+
+	repo, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	commit := func(path, message string, parents ...plumbing.Hash) plumbing.Hash {
+		t.Helper()
+
+		if path != "" {
+			file, err := fs.Create(path)
+			require.NoError(t, err)
+			require.NoError(t, file.Close())
+
+			_, err = worktree.Add(path)
+			require.NoError(t, err)
+		}
+
+		date = date.Add(time.Minute)
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: date}
+
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature, Parents: parents})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	root := commit("charts/project-0/a", "feat: root")
+	branch := commit("charts/project-0/b", "feat: branch")
+
+	_, err = worktree.Remove("charts/project-0/b")
+	require.NoError(t, err)
+
+	main := commit("charts/project-0/c", "fix: main", root)
+	commit("charts/project-0/b", "Merge pull request #12 from user/branch\n\nfeat: branch", main, branch)
+
+	ranges := []history.Range{{Path: "charts/project-0", Stop: root}}
+
+	commits, err := history.Collect(repo, ranges, history.Options{})
+	require.NoError(t, err)
+
+	messages := make([]string, 0, len(commits[0]))
+	for _, commit := range commits[0] {
+		messages = append(messages, commit.Message)
+	}
+
+	assert.Equal(t, []string{"Merge pull request #12 from user/branch\n\nfeat: branch", "fix: main", "feat: branch"}, messages)
+
+	commits, err = history.Collect(repo, ranges, history.Options{FirstParent: true})
+	require.NoError(t, err)
+
+	messages = messages[:0]
+	for _, commit := range commits[0] {
+		messages = append(messages, commit.Message)
+	}
+
+	assert.Equal(t, []string{"Merge pull request #12 from user/branch\n\nfeat: branch", "fix: main"}, messages)
+}
+
+func TestCollectEqualCommitterTime(t *testing.T) {
+	t.Parallel()
+
+	fs := memfs.New()
+
+	repo, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(t, err)
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	commit := func(path, message string, when time.Time) plumbing.Hash {
+		t.Helper()
+
+		file, err := fs.Create(path)
+		require.NoError(t, err)
+		require.NoError(t, file.Close())
+
+		_, err = worktree.Add(path)
+		require.NoError(t, err)
+
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: when}
+
+		hash, err := worktree.Commit(message, &git.CommitOptions{Author: signature, Committer: signature})
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	// releases of one run are created sequentially and share the committer time.
+	base := commit("charts/base/file", "feat: base", date)
+	commit("charts/c/x", "feat: x", date)
+	releaseA := commit("charts/a/CHANGELOG.md", "chore(release): a", date.Add(time.Minute))
+	releaseB := commit("charts/b/CHANGELOG.md", "chore(release): b", date.Add(time.Minute))
+	commit("charts/d/file", "feat: d", date.Add(2*time.Minute))
+
+	ranges := []history.Range{
+		{Path: "charts/a", Stop: releaseA},
+		{Path: "charts/b", Stop: releaseB},
+		{Path: "charts/c", Stop: base},
+	}
+
+	commits, err := history.Collect(repo, ranges, history.Options{})
+	require.NoError(t, err)
+
+	require.Len(t, commits, 3)
+	assert.Empty(t, commits[0])
+	assert.Empty(t, commits[1])
+	require.Len(t, commits[2], 1)
+	assert.Equal(t, "feat: x", commits[2][0].Message)
+}
+
+func TestCollectNoRanges(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := newSyntheticRepository(t, 1, 1, 0)
+
+	commits, err := history.Collect(repo, nil, history.Options{})
+	require.NoError(t, err)
+	assert.Empty(t, commits)
+}
+
+func benchmarkRanges(projects int) []history.Range {
+	ranges := make([]history.Range, projects)
+	for i := range ranges {
+		ranges[i] = history.Range{Path: fmt.Sprintf("charts/project-%d", i)}
+	}
+
+	return ranges
+}
+
+func BenchmarkCollect(b *testing.B) {
+	repo, _ := newSyntheticRepository(b, 20, 500, 0)
+	ranges := benchmarkRanges(20)
+
+	b.ResetTimer()
+
+	for range b.N {
+		_, err := history.Collect(repo, ranges, history.Options{})
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkLogPerPath(b *testing.B) {
+	repo, _ := newSyntheticRepository(b, 20, 500, 0)
+	ranges := benchmarkRanges(20)
+
+	b.ResetTimer()
+
+	for range b.N {
+		logPerPath(b, repo, ranges)
+	}
+}
@@ -0,0 +1,255 @@
+package project
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	cc "github.com/leodido/go-conventionalcommits"
+)
+
+var (
+	regexpMergePullRequest = regexp.MustCompile(`^Merge pull request #(\d+) from \S+`)
+	regexpSquashBodyEntry  = regexp.MustCompile(`^[*-]\s+(\S.*)$`)
+	regexpRevertSubject    = regexp.MustCompile(`^(?:revert(?:\([^)]*\))?!?:|Revert ")`)
+	regexpRevertedCommit   = regexp.MustCompile(`(?m)^This reverts commit ([0-9a-f]{7,40})`)
+	regexpCoAuthoredBy     = regexp.MustCompile(`(?mi)^Co-authored-by:\s*(.*?)\s*<([^>]+)>\s*$`)
+	regexpFooter           = regexp.MustCompile(`^([A-Za-z][\w-]*|BREAKING CHANGE)(: | #)(.+)$`)
+
+	defaultSkipMarkers = []string{"[skip release]", "[no release]"}
+)
+
+// releaseCommitMessage is the subject of the commits created by commitToRepository.
+const releaseCommitMessage = "chore(%s): release %s [skip ci]"
+
+// commitMessages returns the messages of the commit, which are parsed as conventional commits.
+//
+// Merge commits are skipped, if the commits of merged branches are included.
+// Otherwise, the body of the merge commit is used. The number of a merged pull request is appended to the subject.
+// If enabled, list entries in the commit body are returned as additional messages.
+func (c *Project) commitMessages(commit *object.Commit) []string {
+	message := strings.TrimSpace(commit.Message)
+
+	if commit.NumParents() > 1 {
+		if c.conf.MergeCommits != config.MergeCommitsFirstParent {
+			c.logger.Info().Str("message", message).Msg("SKIP merge commit")
+
+			return nil
+		}
+
+		subject, body, _ := strings.Cut(message, "\n")
+		message = strings.TrimSpace(body)
+
+		if message == "" {
+			c.logger.Info().Str("message", subject).Msg("SKIP merge commit without body")
+
+			return nil
+		}
+
+		if match := regexpMergePullRequest.FindStringSubmatch(subject); match != nil {
+			title, rest, _ := strings.Cut(message, "\n")
+			message = fmt.Sprintf("%s (#%s)", title, match[1])
+
+			if rest != "" {
+				message += "\n" + rest
+			}
+		}
+	}
+
+	messages := []string{message}
+
+	if c.conf.ParseSquashBody {
+		messages = append(messages, c.squashBodyMessages(message)...)
+	}
+
+	return messages
+}
+
+// squashBodyMessages returns list entries of the commit body, which are valid conventional commits.
+// Entries equal to the subject are skipped.
+func (c *Project) squashBodyMessages(message string) []string {
+	subject, body, _ := strings.Cut(message, "\n")
+
+	var messages []string
+
+	for _, line := range strings.Split(body, "\n") {
+		match := regexpSquashBodyEntry.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || strings.HasPrefix(subject, match[1]) {
+			continue
+		}
+
+		if _, err := c.parseCommitMessage([]byte(match[1])); err != nil {
+			continue
+		}
+
+		messages = append(messages, match[1])
+	}
+
+	return messages
+}
+
+// dropReverts removes revert commits and the reverted commits from the commits, if both are part of the commits.
+// Reverts of reverts restore the originally reverted commit. The commits are expected newest first.
+func (c *Project) dropReverts(commits []*object.Commit) []*object.Commit {
+	active := make(map[plumbing.Hash]bool, len(commits))
+	// cancelled maps a revert commit to the commit reverted by it.
+	cancelled := map[plumbing.Hash]plumbing.Hash{}
+
+	for i := len(commits) - 1; i >= 0; i-- {
+		commit := commits[i]
+		active[commit.Hash] = true
+
+		match := regexpRevertedCommit.FindStringSubmatch(commit.Message)
+		if match == nil || !regexpRevertSubject.MatchString(commit.Message) {
+			continue
+		}
+
+		reverted, ok := findCommit(commits, match[1])
+		if !ok {
+			continue
+		}
+
+		original, revertsRevert := cancelled[reverted]
+		if !active[reverted] && !revertsRevert {
+			continue
+		}
+
+		c.logger.Info().Str("message", firstLine(commit.Message)).Str("reverted", reverted.String()[:7]).Msg("SKIP revert")
+
+		active[commit.Hash] = false
+		active[reverted] = false
+		cancelled[commit.Hash] = reverted
+
+		// a revert of a revert restores the originally reverted commit.
+		if revertsRevert {
+			active[original] = true
+		}
+	}
+
+	result := make([]*object.Commit, 0, len(commits))
+
+	for _, commit := range commits {
+		if active[commit.Hash] {
+			result = append(result, commit)
+		}
+	}
+
+	return result
+}
+
+// findCommit returns the hash of the commit matching the full or abbreviated hash.
+func findCommit(commits []*object.Commit, hash string) (plumbing.Hash, bool) {
+	for _, commit := range commits {
+		if strings.HasPrefix(commit.Hash.String(), hash) {
+			return commit.Hash, true
+		}
+	}
+
+	return plumbing.ZeroHash, false
+}
+
+// firstLine returns the first line of the message.
+func firstLine(message string) string {
+	line, _, _ := strings.Cut(message, "\n")
+
+	return line
+}
+
+// excludeReason returns the reason, why the commit message is excluded from the release detection.
+// If the message is not excluded, an empty string is returned.
+func (c *Project) excludeReason(commit *object.Commit, commitMessage string, message *cc.ConventionalCommit) string {
+	subject := firstLine(commitMessage)
+
+	if c.regexpReleaseCommit.MatchString(subject) {
+		return "release commit"
+	}
+
+	for _, marker := range c.config.Exclude.Markers {
+		if strings.Contains(commitMessage, marker) {
+			return "marker " + marker
+		}
+	}
+
+	author := fmt.Sprintf("%s <%s>", commit.Author.Name, commit.Author.Email)
+
+	scope := ""
+	if message != nil && message.Scope != nil {
+		scope = *message.Scope
+	}
+
+	for i, rule := range c.config.Exclude.Rules {
+		if rule.Author == nil && rule.Scope == nil && rule.Subject == nil {
+			continue
+		}
+
+		if (rule.Author == nil || rule.Author.MatchString(author)) &&
+			(rule.Scope == nil || rule.Scope.MatchString(scope)) &&
+			(rule.Subject == nil || rule.Subject.MatchString(subject)) {
+			return fmt.Sprintf("rule %d", i)
+		}
+	}
+
+	return ""
+}
+
+// changelogEntry returns the changelog entry of the commit message.
+// The message is the parsed commit message, if the commit message is a conventional commit.
+func changelogEntry(commit *object.Commit, commitMessage string, message *cc.ConventionalCommit) changelog.Entry {
+	entry := changelog.Entry{
+		Hash:    commit.Hash.String()[:7],
+		Message: firstLine(commitMessage),
+		Author:  changelog.Person{Name: commit.Author.Name, Email: commit.Author.Email},
+	}
+
+	if message != nil {
+		entry.Type = message.Type
+		entry.Description = message.Description
+
+		if message.Scope != nil {
+			entry.Scope = *message.Scope
+		}
+	}
+
+	for _, match := range regexpCoAuthoredBy.FindAllStringSubmatch(commit.Message, -1) {
+		entry.CoAuthors = append(entry.CoAuthors, changelog.Person{Name: match[1], Email: match[2]})
+	}
+
+	entry.Footers = parseFooters(commitMessage)
+
+	return entry
+}
+
+// parseFooters returns the git trailers from the last paragraph of the commit message.
+func parseFooters(commitMessage string) []changelog.Footer {
+	paragraphs := strings.Split(strings.TrimSpace(commitMessage), "\n\n")
+	if len(paragraphs) < 2 {
+		return nil
+	}
+
+	var footers []changelog.Footer
+
+	for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+		match := regexpFooter.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+
+		value := match[3]
+		if match[2] == " #" {
+			value = "#" + value
+		}
+
+		footers = append(footers, changelog.Footer{Key: match[1], Value: value})
+	}
+
+	return footers
+}
+
+// releaseCommitRegexp returns a regular expression matching the subject of release commits of the project.
+func releaseCommitRegexp(name string) *regexp.Regexp {
+	return regexp.MustCompile("^" + fmt.Sprintf(regexp.QuoteMeta(releaseCommitMessage), regexp.QuoteMeta(name), `\S+`) + "$")
+}
@@ -0,0 +1,147 @@
+package project
+
+import (
+	"crypto/sha1" //nolint:gosec // only used to generate test hashes
+	"regexp"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	cc "github.com/leodido/go-conventionalcommits"
+	"github.com/leodido/go-conventionalcommits/parser"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func testCommit(message string) *object.Commit {
+	return &object.Commit{Hash: plumbing.Hash(sha1.Sum([]byte(message))), Message: message}
+}
+
+func TestDropReverts(t *testing.T) {
+	t.Parallel()
+
+	feat := testCommit("feat: add x")
+	fix := testCommit("fix: fix y")
+	revertFeat := testCommit("Revert \"feat: add x\"\n\nThis reverts commit " + feat.Hash.String() + ".\n")
+	revertFix := testCommit("revert: fix: fix y\n\nThis reverts commit " + fix.Hash.String()[:7] + ".\n")
+	revertRevertFeat := testCommit("Revert \"Revert \"feat: add x\"\"\n\nThis reverts commit " + revertFeat.Hash.String() + ".\n")
+	revertReleased := testCommit("Revert \"feat: released\"\n\nThis reverts commit 0123456789abcdef0123456789abcdef01234567.\n")
+
+	project := &Project{logger: zerolog.Nop()}
+
+	for _, tc := range []struct {
+		name     string
+		commits  []*object.Commit
+		expected []*object.Commit
+	}{
+		{"no reverts", []*object.Commit{fix, feat}, []*object.Commit{fix, feat}},
+		{"git revert", []*object.Commit{revertFeat, fix, feat}, []*object.Commit{fix}},
+		{"conventional revert", []*object.Commit{revertFix, fix, feat}, []*object.Commit{feat}},
+		{"revert of revert", []*object.Commit{revertRevertFeat, revertFeat, fix, feat}, []*object.Commit{fix, feat}},
+		{"reverted commit already released", []*object.Commit{revertReleased, fix}, []*object.Commit{revertReleased, fix}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.expected, project.dropReverts(tc.commits))
+		})
+	}
+}
+
+func TestExcludeReason(t *testing.T) {
+	t.Parallel()
+
+	project := &Project{
+		logger:              zerolog.Nop(),
+		regexpReleaseCommit: regexp.MustCompile(`^chore\(app\): release \S+ \[skip ci\]$`),
+		config: Config{
+			Exclude: ConfigExclude{
+				Markers: defaultSkipMarkers,
+				Rules: []ConfigExcludeRule{
+					{Author: &Regexp{regexp.MustCompile(`^renovate\[bot\]`)}, Scope: &Regexp{regexp.MustCompile(`^deps-dev$`)}},
+					{Subject: &Regexp{regexp.MustCompile(`^fix: typo`)}},
+				},
+			},
+		},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		author   string
+		message  string
+		expected string
+	}{
+		{"regular commit", "Jane", "feat: add x", ""},
+		{"release commit", "Jane", "chore(app): release 1.2.3 [skip ci]\n\n## 1.2.3", "release commit"},
+		{"release commit of other project", "Jane", "chore(other): release 1.2.3 [skip ci]", ""},
+		{"skip marker", "Jane", "feat: add x [skip release]", "marker [skip release]"},
+		{"skip marker in body", "Jane", "feat: add x\n\n[no release]", "marker [no release]"},
+		{"author and scope", "renovate[bot]", "fix(deps-dev): update x", "rule 0"},
+		{"author with other scope", "renovate[bot]", "fix(deps): update x", ""},
+		{"subject", "Jane", "fix: typo in docs", "rule 1"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			commit := testCommit(tc.message)
+			commit.Author.Name = tc.author
+
+			message, _ := parser.NewMachine(parser.WithTypes(cc.TypesConventional)).Parse([]byte(tc.message))
+			conventionalCommit, _ := message.(*cc.ConventionalCommit)
+
+			assert.Equal(t, tc.expected, project.excludeReason(commit, tc.message, conventionalCommit))
+		})
+	}
+}
+
+func TestCommitMessages(t *testing.T) {
+	t.Parallel()
+
+	squashBody := "feat: squash (#3)\n\n* fix: a\n- feat(api): b\n* not conventional\n* feat: squash\ntext - fix: c"
+
+	for _, tc := range []struct {
+		name            string
+		message         string
+		merge           bool
+		mergeCommits    string
+		parseSquashBody bool
+		expected        []string
+	}{
+		{"regular commit", "fix: x\n", false, config.MergeCommitsInclude, false, []string{"fix: x"}},
+		{"merge commit included", "Merge pull request #12 from a/b\n\nfeat: add x", true, config.MergeCommitsInclude, false, nil},
+		{"merge commit without body", "Merge branch 'b'", true, config.MergeCommitsFirstParent, false, nil},
+		{"merge commit", "Merge branch 'b'\n\nfix: y", true, config.MergeCommitsFirstParent, false, []string{"fix: y"}},
+		{"merge pull request", "Merge pull request #12 from a/b\n\nfeat: add x", true, config.MergeCommitsFirstParent, false, []string{"feat: add x (#12)"}},
+		{
+			"merge pull request with body", "Merge pull request #12 from a/b\n\nfeat: add x\n\nBREAKING CHANGE: y", true,
+			config.MergeCommitsFirstParent, false, []string{"feat: add x (#12)\n\nBREAKING CHANGE: y"},
+		},
+		{"squash body disabled", squashBody, false, config.MergeCommitsInclude, false, []string{squashBody}},
+		{"squash body", squashBody, false, config.MergeCommitsInclude, true, []string{squashBody, "fix: a", "feat(api): b"}},
+		{
+			"merge pull request with squash body", "Merge pull request #12 from a/b\n\nfeat: add x\n\n* fix: a", true,
+			config.MergeCommitsFirstParent, true, []string{"feat: add x (#12)\n\n* fix: a", "fix: a"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			commitParser := parser.NewMachine(parser.WithTypes(cc.TypesConventional))
+			commitParser.WithBestEffort()
+
+			project := &Project{
+				logger:       zerolog.Nop(),
+				conf:         &config.Config{MergeCommits: tc.mergeCommits, ParseSquashBody: tc.parseSquashBody},
+				commitParser: commitParser,
+			}
+
+			commit := testCommit(tc.message)
+			if tc.merge {
+				commit.ParentHashes = []plumbing.Hash{testCommit("a").Hash, testCommit("b").Hash}
+			}
+
+			assert.Equal(t, tc.expected, project.commitMessages(commit))
+		})
+	}
+}
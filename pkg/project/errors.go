@@ -5,6 +5,17 @@ import (
 )
 
 var (
-	ErrProjectFileNotFound = errors.New("file Project.yaml not found")
-	ErrMultipleMatchInTag  = errors.New("multiple matches in tag")
+	ErrProjectFileNotFound  = errors.New("file Project.yaml not found")
+	ErrReleaseAsNotGreater  = errors.New("release-as version must be greater than the current version")
+	ErrHookCommandAndArgs   = errors.New("hook command and args are mutually exclusive")
+	ErrHookShellAndArgs     = errors.New("hook shell and args are mutually exclusive, args are executed without a shell")
+	ErrInvalidRetryAttempts = errors.New("invalid retry.attempts, expected at least 1")
+	ErrInvalidRetryBackoff  = errors.New("invalid retry.backoff, expected a positive duration")
+	ErrInvalidRecovery      = errors.New("invalid recovery.onPublishFailure, must be none, deleteTag or record")
+	ErrTagNotOfProject      = errors.New("tag does not belong to the project")
+	ErrReleaseOutdated      = errors.New("the remote contains new commits or a release of the project, rerun the release")
+	ErrRootReleaseCommit    = errors.New("the release commit has no parent")
+	ErrPublishedNotPushed   = errors.New("the version is published, but the release commit and tag are not pushed")
+	ErrRecoveryConflict     = errors.New("recovery.publishBeforePush and recovery.onPublishFailure are mutually exclusive")
+	ErrPublishBeforePushSha = errors.New("recovery.publishBeforePush does not support {sha} and {shortSha} in the git tag pattern")
 )
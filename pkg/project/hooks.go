@@ -0,0 +1,328 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/command"
+	"github.com/jkroepke/semantic-releaser/pkg/mask"
+)
+
+// stage is a stage of the release lifecycle. Each stage runs the hook command of the same name.
+type stage string
+
+const (
+	// stageVerifyConditions runs first for each project, before the release detection. Only the read-only history
+	// walk, shared by all projects, precedes it. A failure fails the project, the other projects are still released.
+	stageVerifyConditions stage = "verifyConditions"
+	// stageVerifyRelease runs after the next version is computed. A failure aborts the release without any changes.
+	stageVerifyRelease stage = "verifyRelease"
+	// stageSetNewVersion writes the next version into the project files.
+	stageSetNewVersion stage = "setNewVersion"
+	// stagePrepare builds or packages the project before the release commit. A failure aborts before the tag is pushed.
+	stagePrepare stage = "prepare"
+	// stagePublish runs after the release commit and tag are pushed.
+	stagePublish stage = "publishNewVersion"
+	// stageSuccess runs after a successful release. A failure is logged, since the release is already public.
+	stageSuccess stage = "success"
+	// stageFail runs after any other stage failed. The error is available as {{ .error }}.
+	stageFail stage = "fail"
+)
+
+// VerifyConditions runs the verifyConditions hook. It should be called before DetectRelease.
+func (c *Project) VerifyConditions(ctx context.Context) error {
+	if err := c.runHook(ctx, stageVerifyConditions, c.config.Commands.VerifyConditions, c.templateData(nil, nil)); err != nil {
+		return c.fail(ctx, c.templateData(nil, nil), err)
+	}
+
+	return nil
+}
+
+// Release runs the release lifecycle of the project: verifyRelease, setNewVersion, prepare,
+// commit and push of the release, publishNewVersion and success. If a stage fails, the fail hook is run.
+// The changes of the repository are made while holding gitLock, which is shared by projects released in parallel.
+func (c *Project) Release(ctx context.Context, version semver.Version, changelogEntries *changelog.Changelog, gitLock sync.Locker) error {
+	c.logger.Info().Str("version", version.String()).Msg("releasing project")
+
+	data := c.templateData(&version, changelogEntries)
+
+	if err := c.release(ctx, version, changelogEntries, data, gitLock); err != nil {
+		return c.fail(ctx, data, err)
+	}
+
+	if err := c.runHook(ctx, stageSuccess, c.config.Commands.Success, data); err != nil {
+		c.logger.Warn().Err(err).Str("version", version.String()).Msg("success hook failed")
+	}
+
+	return nil
+}
+
+// release runs the stages of the release. Once the release commit exists, its hash and tag name are set in data.
+// The commit, push and recovery run while holding gitLock. With PublishBeforePush, the publishNewVersion hook
+// runs while holding gitLock as well, since the local release commit is not pushed yet.
+func (c *Project) release(
+	ctx context.Context, version semver.Version, changelogEntries *changelog.Changelog, data map[string]any, gitLock sync.Locker,
+) error {
+	if err := c.runHook(ctx, stageVerifyRelease, c.config.Commands.VerifyRelease, data); err != nil {
+		return err
+	}
+
+	if err := c.runHook(ctx, stageSetNewVersion, c.config.Commands.SetNewVersion, data); err != nil {
+		return err
+	}
+
+	if err := c.runHook(ctx, stagePrepare, c.config.Commands.Prepare, data); err != nil {
+		return err
+	}
+
+	gitLock.Lock()
+	defer gitLock.Unlock()
+
+	commit, err := c.commitToRepository(version, changelogEntries)
+	if err != nil {
+		return fmt.Errorf("failed to commit to repository: %w", err)
+	}
+
+	data["commitSha"] = commit.String()
+	data["tagName"] = c.getGitTag(version, commit.String())
+
+	if c.config.Recovery.PublishBeforePush {
+		if err = c.runHook(ctx, stagePublish, c.config.Commands.Publish, data); err != nil {
+			return c.discardRelease(version, commit, err)
+		}
+
+		if _, _, err = c.push(ctx, version, commit); err != nil {
+			// the artifacts are public, but the next run releases the version again.
+			c.logger.Error().Err(err).Str("tag", data["tagName"].(string)). //nolint:forcetypeassert
+											Msg("version is published, but the release commit and tag are not pushed, push them manually")
+
+			return fmt.Errorf("failed to push to repository after publishing %s: %w", data["tagName"], errors.Join(ErrPublishedNotPushed, err))
+		}
+
+		return nil
+	}
+
+	commit, tagName, err := c.push(ctx, version, commit)
+	if err != nil {
+		return fmt.Errorf("failed to push to repository: %w", err)
+	}
+
+	// the release commit may be reapplied onto the moved remote branch.
+	data["commitSha"] = commit.String()
+	data["tagName"] = tagName
+
+	// the other projects may commit and push, while the publishNewVersion hook runs.
+	gitLock.Unlock()
+
+	err = c.runHook(ctx, stagePublish, c.config.Commands.Publish, data)
+
+	gitLock.Lock()
+
+	if err != nil {
+		return c.recoverPublish(ctx, commit, tagName, err)
+	}
+
+	return nil
+}
+
+// failHookTimeout bounds the fail hook after the release was canceled, e.g. by SIGTERM.
+const failHookTimeout = 30 * time.Second
+
+// fail runs the fail hook and returns the original error, joined with the error of the fail hook.
+// After a cancellation, the fail hook still runs, bounded by failHookTimeout.
+func (c *Project) fail(ctx context.Context, data map[string]any, err error) error {
+	data["error"] = err.Error()
+
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), failHookTimeout)
+		defer cancel()
+	}
+
+	if failErr := c.runHook(ctx, stageFail, c.config.Commands.Fail, data); failErr != nil {
+		return errors.Join(err, failErr)
+	}
+
+	return err
+}
+
+// runHook renders the command template of the stage and executes it in the project directory of the worktree.
+// Empty commands are skipped.
+func (c *Project) runHook(ctx context.Context, stage stage, hook Hook, data map[string]any) error {
+	if hook.empty() {
+		return nil
+	}
+
+	args := make([]string, len(hook.Args))
+
+	for i, arg := range hook.Args {
+		var err error
+		if args[i], err = renderTemplate(stage, arg, data); err != nil {
+			return err
+		}
+	}
+
+	cmd, err := renderTemplate(stage, hook.Command, data)
+	if err != nil {
+		return err
+	}
+
+	shell := c.config.Commands.Shell
+	if len(hook.Shell) != 0 {
+		shell = hook.Shell
+	}
+
+	env, cleanup, err := hookEnv(data)
+	if err != nil {
+		return fmt.Errorf("failed to prepare %s hook environment: %w", stage, err)
+	}
+
+	defer cleanup()
+
+	dir, err := c.hookDir()
+	if err != nil {
+		return err
+	}
+
+	logger := c.logger.With().Str("stage", string(stage)).Logger()
+
+	for attempt := 1; ; attempt++ {
+		opts := command.Options{
+			Dir:     dir,
+			Env:     env,
+			Timeout: hook.Timeout,
+			Logger:  logger,
+			Masker:  c.masker,
+			Shell:   shell,
+		}
+
+		if len(args) != 0 {
+			logger.Debug().Int("attempt", attempt).Strs("args", maskAll(c.masker, args)).Msg("running hook")
+
+			err = command.RunArgs(ctx, args, opts)
+		} else {
+			logger.Debug().Int("attempt", attempt).Str("command", c.masker.Mask(cmd)).Msg("running hook")
+
+			err = command.Run(ctx, cmd, opts)
+		}
+
+		if err == nil {
+			return nil
+		}
+
+		if attempt >= hook.Retry.Attempts || !hook.Retry.retryable(ctx, err) {
+			return fmt.Errorf("%s hook failed: %w", stage, err)
+		}
+
+		backoff := hook.Retry.backoff(attempt)
+		logger.Warn().Err(err).Int("attempt", attempt).Dur("backoff", backoff).Msg("hook failed, retrying")
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s hook failed: %w", stage, errors.Join(err, ctx.Err()))
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// hookDir returns the project directory inside the worktree. Hooks run there, regardless of the working directory
+// of the process, e.g. if the repository is opened from a parent directory.
+func (c *Project) hookDir() (string, error) {
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	return filepath.Join(worktree.Filesystem.Root(), c.projectPath), nil
+}
+
+// maskAll returns the masked values.
+func maskAll(masker *mask.Masker, values []string) []string {
+	masked := make([]string, len(values))
+
+	for i, value := range values {
+		masked[i] = masker.Mask(value)
+	}
+
+	return masked
+}
+
+// maxRetryBackoff caps the exponential backoff of hook retries.
+const maxRetryBackoff = 5 * time.Minute
+
+// backoff returns the delay after the failed attempt. The delay doubles after each attempt, up to maxRetryBackoff.
+func (r Retry) backoff(attempt int) time.Duration {
+	backoff := r.Backoff
+
+	for i := 1; i < attempt && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxRetryBackoff)
+}
+
+// retryable reports whether the failure of a hook command should be retried.
+// Canceled commands are never retried, timeouts of the command itself are.
+func (r Retry) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	var commandErr *command.Error
+	if !errors.As(err, &commandErr) {
+		return false
+	}
+
+	if len(r.ExitCodes) == 0 && r.Output == nil {
+		return true
+	}
+
+	if slices.Contains(r.ExitCodes, commandErr.ExitCode) {
+		return true
+	}
+
+	return r.Output != nil && (r.Output.MatchString(commandErr.Stdout) || r.Output.MatchString(commandErr.Stderr))
+}
+
+// hookEnv returns the environment variables of hook commands, derived from the template context.
+// The release notes are written into a temporary file, which is removed by cleanup.
+func hookEnv(data map[string]any) ([]string, func(), error) {
+	releaseNotes, err := os.CreateTemp("", "semrel-release-notes-*.md")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create release notes file: %w", err)
+	}
+
+	cleanup := func() {
+		_ = os.Remove(releaseNotes.Name())
+	}
+
+	_, err = fmt.Fprint(releaseNotes, data["changelog"])
+	if closeErr := releaseNotes.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		cleanup()
+
+		return nil, nil, fmt.Errorf("failed to write release notes file: %w", err)
+	}
+
+	return []string{
+		fmt.Sprintf("SEMREL_PROJECT_NAME=%s", data["projectName"]),
+		fmt.Sprintf("SEMREL_PROJECT_PATH=%s", data["projectPath"]),
+		fmt.Sprintf("SEMREL_CURRENT_VERSION=%s", data["currentVersion"]),
+		fmt.Sprintf("SEMREL_NEXT_VERSION=%s", data["nextVersion"]),
+		fmt.Sprintf("SEMREL_BUMP=%s", data["bump"]),
+		fmt.Sprintf("SEMREL_TAG=%s", data["tagName"]),
+		"SEMREL_RELEASE_NOTES_FILE=" + releaseNotes.Name(),
+	}, cleanup, nil
+}
@@ -0,0 +1,254 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestHooks(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		commands ConfigCommands
+		release  bool
+		expected string
+		err      string
+	}{
+		{
+			"verify conditions fails",
+			ConfigCommands{
+				VerifyConditions: Hook{Command: "echo verifyConditions >> hooks.log; exit 1"},
+				Fail:             Hook{Command: "echo 'fail {{ .nextVersion }}' >> hooks.log"},
+			},
+			false,
+			"verifyConditions\nfail \n",
+			"verifyConditions hook failed",
+		},
+		{
+			"prepare fails before commit",
+			ConfigCommands{
+				VerifyRelease: Hook{Command: "echo 'verifyRelease {{ .nextVersion }}' >> hooks.log"},
+				SetNewVersion: Hook{Command: "echo 'setNewVersion {{ .nextVersion }}' >> hooks.log"},
+				Prepare:       Hook{Command: "echo \"prepare $SEMREL_BUMP $SEMREL_TAG $(grep -c \"fix it\" \"$SEMREL_RELEASE_NOTES_FILE\")\" >> hooks.log; exit 1"},
+				Publish:       Hook{Command: "echo publish >> hooks.log"},
+				Success:       Hook{Command: "echo success >> hooks.log"},
+				Fail:          Hook{Command: "echo 'fail {{ .projectName }}' >> hooks.log"},
+			},
+			true,
+			"verifyRelease 1.2.3\nsetNewVersion 1.2.3\nprepare patch test/v1.2.3 1\nfail test\n",
+			"prepare hook failed",
+		},
+		{
+			"timeout",
+			ConfigCommands{
+				VerifyConditions: Hook{Command: "sleep 10", Timeout: 100 * time.Millisecond},
+			},
+			false,
+			"",
+			"context deadline exceeded",
+		},
+		{
+			"retry",
+			ConfigCommands{
+				VerifyConditions: Hook{
+					Command: "echo attempt >> hooks.log; [ $(wc -l < hooks.log) -ge 3 ]",
+					Retry:   Retry{Attempts: 3, Backoff: time.Millisecond},
+				},
+			},
+			false,
+			"attempt\nattempt\nattempt\n",
+			"",
+		},
+		{
+			"retry exit code not matching",
+			ConfigCommands{
+				VerifyConditions: Hook{Command: "echo attempt >> hooks.log; exit 1", Retry: Retry{Attempts: 3, ExitCodes: []int{75}}},
+			},
+			false,
+			"attempt\n",
+			"exit status 1",
+		},
+		{
+			"retry output matching",
+			ConfigCommands{
+				VerifyConditions: Hook{
+					Command: "echo attempt >> hooks.log; echo 'HTTP 503' >&2; exit 1",
+					Retry:   Retry{Attempts: 2, Output: &Regexp{regexp.MustCompile(`\b503\b`)}},
+				},
+			},
+			false,
+			"attempt\nattempt\n",
+			"exit status 1",
+		},
+		{
+			"args without shell",
+			ConfigCommands{
+				VerifyConditions: Hook{Args: []string{"sh", "-c", `printf '%s\n' "$1" >> hooks.log`, "sh", "{{ .projectName }} it's; exit 1"}},
+			},
+			false,
+			"test it's; exit 1\n",
+			"",
+		},
+		{
+			"shell",
+			ConfigCommands{
+				Shell:            []string{"bash", "-euo", "pipefail", "-c"},
+				VerifyConditions: Hook{Command: "false | true; echo ok >> hooks.log"},
+			},
+			false,
+			"",
+			"exit status 1",
+		},
+		{
+			"fail hook fails",
+			ConfigCommands{
+				VerifyRelease: Hook{Command: "exit 1"},
+				Fail:          Hook{Command: "exit 2"},
+			},
+			true,
+			"",
+			"fail hook failed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newTestProject(t)
+			project.projectPath = t.TempDir()
+			project.config.Commands = tc.commands
+
+			var err error
+			if tc.release {
+				changes := changelog.New()
+				changes.AddFix("fix it", "1234567")
+
+				err = project.Release(context.Background(), *semver.New(1, 2, 3, "", ""), changes, &sync.Mutex{})
+			} else {
+				err = project.VerifyConditions(context.Background())
+			}
+
+			if tc.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, tc.err)
+			}
+
+			log, _ := os.ReadFile(filepath.Join(project.projectPath, "hooks.log"))
+			assert.Equal(t, tc.expected, string(log))
+		})
+	}
+}
+
+func TestFailHookAfterCancel(t *testing.T) {
+	t.Parallel()
+
+	project := newTestProject(t)
+	project.projectPath = t.TempDir()
+	project.config.Commands = ConfigCommands{
+		VerifyConditions: Hook{Command: "echo verifyConditions >> hooks.log"},
+		Fail:             Hook{Command: "echo fail >> hooks.log"},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.ErrorIs(t, project.VerifyConditions(ctx), context.Canceled)
+
+	log, err := os.ReadFile(filepath.Join(project.projectPath, "hooks.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "fail\n", string(log))
+}
+
+func TestHookUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	var commands ConfigCommands
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+prepare: make package
+setNewVersion: ["helm", "package", "{{ .projectPath }}"]
+publishNewVersion:
+  command: helm push
+  timeout: 5m
+`), &commands))
+
+	assert.Equal(t, Hook{Command: "make package"}, commands.Prepare)
+	assert.Equal(t, Hook{Command: "helm push", Timeout: 5 * time.Minute}, commands.Publish)
+	assert.Equal(t, Hook{Args: []string{"helm", "package", "{{ .projectPath }}"}}, commands.SetNewVersion)
+
+	err := yaml.Unmarshal([]byte("prepare:\n  command: make\n  args: [make]\n"), &commands)
+	require.ErrorIs(t, err, ErrHookCommandAndArgs)
+
+	err = yaml.Unmarshal([]byte("prepare:\n  shell: [bash, -c]\n  args: [make]\n"), &ConfigCommands{})
+	require.ErrorIs(t, err, ErrHookShellAndArgs)
+
+	commands = ConfigCommands{}
+
+	require.NoError(t, yaml.Unmarshal([]byte(`
+shell: [bash, -c]
+prepare:
+  command: make package
+  shell: [sh, -c, 'set -eu; eval "$1"', sh]
+`), &commands))
+
+	assert.Equal(t, []string{"bash", "-c"}, commands.Shell)
+	assert.Equal(t, Hook{Command: "make package", Shell: []string{"sh", "-c", `set -eu; eval "$1"`, "sh"}}, commands.Prepare)
+}
+
+func TestRetryUnmarshalYAML(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		content  string
+		expected Retry
+		err      error
+	}{
+		{"default attempts", "backoff: 1s", Retry{Attempts: 1, Backoff: time.Second}, nil},
+		{"attempts", "attempts: 3\nexitCodes: [75]", Retry{Attempts: 3, ExitCodes: []int{75}}, nil},
+		{"zero attempts", "attempts: 0", Retry{}, ErrInvalidRetryAttempts},
+		{"negative attempts", "attempts: -1", Retry{}, ErrInvalidRetryAttempts},
+		{"negative backoff", "attempts: 2\nbackoff: -1s", Retry{}, ErrInvalidRetryBackoff},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var retry Retry
+
+			err := yaml.Unmarshal([]byte(tc.content), &retry)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, retry)
+		})
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	t.Parallel()
+
+	retry := Retry{Backoff: time.Second}
+
+	assert.Equal(t, time.Second, retry.backoff(1))
+	assert.Equal(t, 4*time.Second, retry.backoff(3))
+	assert.Equal(t, maxRetryBackoff, retry.backoff(20))
+	// a shift by the attempt overflows.
+	assert.Equal(t, maxRetryBackoff, retry.backoff(1000))
+	assert.Equal(t, time.Duration(0), Retry{}.backoff(1000))
+}
@@ -1,21 +1,24 @@
 package project
 
 import (
-	"bytes"
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
-	"regexp"
+	"strconv"
 	"strings"
-	"text/template"
+	"time"
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jkroepke/semantic-releaser/pkg/changelog"
-	"github.com/jkroepke/semantic-releaser/pkg/command"
 	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/history"
+	"github.com/jkroepke/semantic-releaser/pkg/mask"
+	"github.com/jkroepke/semantic-releaser/pkg/tag"
 	"github.com/jkroepke/semantic-releaser/pkg/utils"
 	cc "github.com/leodido/go-conventionalcommits"
 	"github.com/rs/zerolog"
@@ -26,73 +29,59 @@ func New(
 	logger zerolog.Logger, conf *config.Config, repo *git.Repository, commitParser cc.Machine, name string,
 ) (*Project, error) {
 	project := &Project{
-		logger:         logger,
-		conf:           conf,
-		repo:           repo,
-		commitParser:   commitParser,
-		name:           name,
-		projectPath:    filepath.Join(conf.ProjectsDir, name),
-		currentVersion: semver.New(0, 0, 0, "", ""),
-	}
-
-	if err := project.readProjectConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read project config: %w", err)
+		logger:              logger.With().Str("project", name).Logger(),
+		conf:                conf,
+		repo:                repo,
+		commitParser:        commitParser,
+		name:                name,
+		projectPath:         filepath.Join(conf.ProjectsDir, name),
+		currentVersion:      semver.New(0, 0, 0, "", ""),
+		regexpReleaseCommit: releaseCommitRegexp(name),
+		config: Config{
+			Exclude: ConfigExclude{Markers: defaultSkipMarkers},
+		},
+		masker: mask.FromEnv(conf.MaskEnv),
 	}
 
-	if err := project.readCurrentVersion(); err != nil {
-		return nil, fmt.Errorf("failed to read current version: %w", err)
+	tagPattern, err := tag.New(conf.GitTagPattern, conf.GitTagVersionPrefix, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse git tag pattern: %w", err)
 	}
 
-	return project, nil
-}
-
-func (c *Project) CurrentVersion() string {
-	return c.currentVersion.String()
-}
-
-func (c *Project) Release(version semver.Version, changelogEntries *changelog.Changelog) error {
-	c.logger.Info().Str("version", version.String()).Msg("releasing project")
+	project.tagPattern = tagPattern
 
-	if err := c.setVersion(version); err != nil {
-		return fmt.Errorf("failed to set version: %w", err)
-	}
+	for _, pattern := range conf.GitTagLegacyPatterns {
+		legacyTagPattern, err := tag.New(pattern, "", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse legacy git tag pattern: %w", err)
+		}
 
-	if err := c.commitToRepository(version, changelogEntries); err != nil {
-		return fmt.Errorf("failed to commit to repository: %w", err)
+		project.legacyTagPatterns = append(project.legacyTagPatterns, legacyTagPattern)
 	}
 
-	if err := c.publish(version); err != nil {
-		return fmt.Errorf("failed to publish: %w", err)
+	if err := project.readProjectConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read project config: %w", err)
 	}
 
-	return nil
-}
-
-func (c *Project) setVersion(version semver.Version) error {
-	if c.config.Commands.SetNewVersion == "" {
-		return nil
+	if err := project.readCurrentVersion(nil); err != nil {
+		return nil, fmt.Errorf("failed to read current version: %w", err)
 	}
 
-	tmpl, err := template.New("publish").Parse(c.config.Commands.SetNewVersion)
-	if err != nil {
-		return fmt.Errorf("failed to parse set version command template: %w", err)
+	if project.currentTag == "" {
+		if err := project.readInitialVersion(); err != nil {
+			return nil, fmt.Errorf("failed to read initial version: %w", err)
+		}
 	}
 
-	var buf bytes.Buffer
-
-	if err = tmpl.Execute(&buf, map[string]string{
-		"nextVersion": version.String(),
-		"projectName": c.name,
-		"projectPath": c.projectPath,
-	}); err != nil {
-		return fmt.Errorf("failed to execute set version command template: %w", err)
-	}
+	return project, nil
+}
 
-	if err = command.Run(buf.String(), c.projectPath); err != nil {
-		return fmt.Errorf("failed to publish: %w", err)
-	}
+func (c *Project) Name() string {
+	return c.name
+}
 
-	return nil
+func (c *Project) CurrentVersion() string {
+	return c.currentVersion.String()
 }
 
 // readProjectConfig reads the project configuration from the project config file.
@@ -117,213 +106,450 @@ func (c *Project) readProjectConfig() error {
 		return fmt.Errorf("failed to YAML decode %s: %w", c.conf.ConfigFilePath, err)
 	}
 
-	return nil
-}
+	switch c.config.Recovery.OnPublishFailure {
+	case "", RecoveryNone, RecoveryDeleteTag, RecoveryRecord:
+	default:
+		return fmt.Errorf("%q: %w", c.config.Recovery.OnPublishFailure, ErrInvalidRecovery)
+	}
 
-func (c *Project) publish(version semver.Version) error {
-	if c.config.Commands.Publish == "" {
-		return nil
+	if c.config.Recovery.PublishBeforePush {
+		// nothing is pushed before publishing, there is nothing to recover.
+		if c.config.Recovery.OnPublishFailure != "" && c.config.Recovery.OnPublishFailure != RecoveryNone {
+			return ErrRecoveryConflict
+		}
+
+		// the tag name passed to publishNewVersion changes, if the release commit is reapplied before the push.
+		if c.tagPattern.HasCommitHash() {
+			return fmt.Errorf("%q: %w", c.tagPattern, ErrPublishBeforePushSha)
+		}
 	}
 
-	tmpl, err := template.New("publish").Parse(c.config.Commands.Publish)
+	return nil
+}
+
+// readCurrentVersion reads the current version from the git tags.
+// Tags matching the legacy tag patterns are considered as well. Tags without a valid semver version are skipped.
+// If below is not nil, only versions lower than below are considered.
+func (c *Project) readCurrentVersion(below *semver.Version) error {
+	tags, err := c.repo.Tags()
 	if err != nil {
-		return fmt.Errorf("failed to parse publish command template: %w", err)
+		return fmt.Errorf("failed to get tags: %w", err)
 	}
 
-	var buf bytes.Buffer
+	if err = tags.ForEach(func(ref *plumbing.Reference) error {
+		version, ok, err := c.parseTag(ref.Name().Short())
+		if err != nil {
+			// a single malformed tag, e.g. with leading zeros, must not block the project.
+			c.logger.Warn().Err(err).Str("tag", ref.Name().Short()).Msg("skipping tag with invalid version")
 
-	if err = tmpl.Execute(&buf, map[string]string{
-		"nextVersion": version.String(),
-		"projectName": c.name,
-		"projectPath": c.projectPath,
+			return nil
+		}
+
+		if !ok || (below != nil && !version.LessThan(below)) {
+			return nil
+		}
+
+		if c.currentTag == "" || version.GreaterThan(c.currentVersion) {
+			c.currentVersion = version
+			c.currentTag = ref.Name().Short()
+		}
+
+		return nil
 	}); err != nil {
-		return fmt.Errorf("failed to execute publish command template: %w", err)
+		return fmt.Errorf("failed to iterate tags: %w", err)
 	}
 
-	if err = command.Run(buf.String(), c.projectPath); err != nil {
-		return fmt.Errorf("failed to publish: %w", err)
+	return nil
+}
+
+// parseTag parses the version of the tag. Tags matching the legacy tag patterns are considered as well.
+func (c *Project) parseTag(tagName string) (*semver.Version, bool, error) {
+	for _, tagPattern := range append([]tag.Pattern{c.tagPattern}, c.legacyTagPatterns...) {
+		version, ok, err := tagPattern.Parse(tagName)
+		if err != nil || ok {
+			return version, ok, err //nolint:wrapcheck
+		}
 	}
 
-	return nil
+	return nil, false, nil
 }
 
-// readCurrentVersion reads the current version from the git repository file.
-func (c *Project) readCurrentVersion() error {
-	tags, err := c.repo.Tags()
+// releaseDate returns the date of the release for reproducible changelogs and release commits.
+// If SOURCE_DATE_EPOCH is set, it is used. Otherwise, the commit date of HEAD is used.
+func (c *Project) releaseDate() (time.Time, error) {
+	if sourceDateEpoch, ok := os.LookupEnv("SOURCE_DATE_EPOCH"); ok {
+		seconds, err := strconv.ParseInt(sourceDateEpoch, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("failed to parse SOURCE_DATE_EPOCH: %w", err)
+		}
+
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+
+	head, err := c.repo.Head()
 	if err != nil {
-		return fmt.Errorf("failed to get tags: %w", err)
+		return time.Time{}, fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	commit, err := c.repo.CommitObject(head.Hash())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get HEAD commit: %w", err)
 	}
 
-	tagRegex := strings.NewReplacer(`\{project\}`, c.name, `\{version\}`, "(.*)").
-		Replace(regexp.QuoteMeta(c.conf.GitTagPattern))
+	return commit.Committer.When, nil
+}
 
-	regTagPattern, err := regexp.Compile(tagRegex)
+// readUsernames reads the mapping from email addresses to usernames.
+func (c *Project) readUsernames() (map[string]string, error) {
+	worktree, err := c.repo.Worktree()
 	if err != nil {
-		return fmt.Errorf("failed to compile tag pattern: %w", err)
+		return nil, fmt.Errorf("failed to get worktree: %w", err)
 	}
 
-	if err = tags.ForEach(func(tag *plumbing.Reference) error {
-		found := regTagPattern.FindAllStringSubmatch(tag.Name().Short(), 1)
-		switch len(found) {
-		case 0:
-			return nil
-		case 1:
-			version, err := semver.NewVersion(found[0][1])
-			if err != nil {
-				return fmt.Errorf("failed to parse version %q from tag %q: %w", found[0][1], tag.Name().Short(), err)
-			}
+	file, err := worktree.Filesystem.Open(c.config.Changelog.UsernamesFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", c.config.Changelog.UsernamesFile, err)
+	}
+	defer file.Close()
 
-			if version.GreaterThan(c.currentVersion) {
-				c.currentVersion = version
-			}
+	usernames := map[string]string{}
 
-			return nil
-		default:
-			return fmt.Errorf("%s: %w", tag.Name().Short(), ErrMultipleMatchInTag)
+	if err = yaml.NewDecoder(file).Decode(&usernames); err != nil {
+		return nil, fmt.Errorf("failed to YAML decode %s: %w", c.config.Changelog.UsernamesFile, err)
+	}
+
+	return usernames, nil
+}
+
+// readInitialVersion reads the version of the first release from the project config.
+func (c *Project) readInitialVersion() error {
+	initialVersion := c.config.InitialVersion
+
+	if c.config.InitialVersionFile != "" {
+		worktree, err := c.repo.Worktree()
+		if err != nil {
+			return fmt.Errorf("failed to get worktree: %w", err)
 		}
-	}); err != nil {
-		return fmt.Errorf("failed to iterate tags: %w", err)
+
+		file, err := worktree.Filesystem.Open(filepath.Join(c.projectPath, c.config.InitialVersionFile))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", c.config.InitialVersionFile, err)
+		}
+		defer file.Close()
+
+		var versionFile struct {
+			Version string `yaml:"version"`
+		}
+
+		if err = yaml.NewDecoder(file).Decode(&versionFile); err != nil {
+			return fmt.Errorf("failed to YAML decode %s: %w", c.config.InitialVersionFile, err)
+		}
+
+		initialVersion = versionFile.Version
+	}
+
+	if initialVersion == "" {
+		return nil
+	}
+
+	version, err := semver.NewVersion(initialVersion)
+	if err != nil {
+		return fmt.Errorf("failed to parse initial version %q: %w", initialVersion, err)
 	}
 
+	c.initialVersion = version
+
 	return nil
 }
 
-func (c *Project) commitToRepository(version semver.Version, changelogEntries *changelog.Changelog) error {
+// commitToRepository writes the changelog and creates the release commit and tag locally.
+func (c *Project) commitToRepository(version semver.Version, changelogEntries *changelog.Changelog) (plumbing.Hash, error) {
 	worktree, err := c.repo.Worktree()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	head, err := c.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD: %w", err)
 	}
 
-	file, err := worktree.Filesystem.Open(filepath.Join(c.projectPath, "CHANGELOG.md"))
+	parent, err := c.repo.CommitObject(head.Hash())
 	if err != nil {
-		return fmt.Errorf("failed to open changelog: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+
+	// the release commit must not be older than its parent, e.g. if SOURCE_DATE_EPOCH predates HEAD. The changelog
+	// carries the date of the release commit.
+	commitDate := changelogEntries.Date()
+	if commitDate.Before(parent.Committer.When) {
+		commitDate = parent.Committer.When
+	}
+
+	changelogEntries.SetDate(commitDate)
+
+	changelogFile := filepath.Join(c.projectPath, changelogEntries.FileName())
+
+	file, err := worktree.Filesystem.OpenFile(changelogFile, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to open changelog: %w", err)
 	}
 	defer file.Close()
 
 	err = changelogEntries.WriteTo(file)
 	if err != nil {
-		return fmt.Errorf("failed to write changelog: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to write changelog: %w", err)
 	}
 
-	_, err = worktree.Add(filepath.Join(c.projectPath, "CHANGELOG.md"))
+	_, err = worktree.Add(changelogFile)
 	if err != nil {
-		return fmt.Errorf("failed to add CHANGELOG.md: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to add %s: %w", changelogFile, err)
 	}
 
+	// the commit message always contains the markdown summary, regardless of the changelog format.
 	changelogSummarize := ""
-	if changelogEntries != nil {
-		changelogSummarize = "\n\n" + changelogEntries.String()
+	if changelogEntries.Len() != 0 {
+		changelogSummarize = "\n\n" + changelog.Markdown{}.Render(changelogEntries)
+	}
+
+	commitMessage := fmt.Sprintf(releaseCommitMessage, c.name, version.String()) + changelogSummarize
+
+	commitOptions := &git.CommitOptions{AllowEmptyCommits: false}
+
+	// loads the author and committer from the git config.
+	if err = commitOptions.Validate(c.repo); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get commit signature: %w", err)
 	}
 
-	commitMessage := fmt.Sprintf("chore(%s): release %s [skip ci]%s", c.name, version.String(), changelogSummarize)
+	commitOptions.Author.When = commitDate
+	commitOptions.Committer.When = commitDate
 
-	commit, err := worktree.Commit(commitMessage, &git.CommitOptions{
-		AllowEmptyCommits: false,
-	})
+	commit, err := worktree.Commit(commitMessage, commitOptions)
 	if err != nil {
-		return fmt.Errorf("failed to commit: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to commit: %w", err)
 	}
 
-	_, err = c.repo.CreateTag(c.getGitTag(version.String()), commit, nil)
+	_, err = c.repo.CreateTag(c.getGitTag(version, commit.String()), commit, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create tag: %w", err)
+		return plumbing.ZeroHash, fmt.Errorf("failed to create tag: %w", err)
 	}
 
-	err = c.repo.Push(&git.PushOptions{
-		FollowTags: true,
-	})
+	return commit, nil
+}
+
+// getGitTag returns the name of the git tag for the version on the given commit.
+func (c *Project) getGitTag(version semver.Version, commitHash string) string {
+	return c.tagPattern.Format(version, commitHash)
+}
+
+// HistoryRange returns the range of the git history containing the unreleased commits of the project.
+func (c *Project) HistoryRange() (history.Range, error) {
+	historyRange := history.Range{Path: filepath.ToSlash(c.projectPath)}
+
+	if c.currentTag == "" {
+		return historyRange, nil
+	}
+
+	ref, err := c.repo.Tag(c.currentTag)
 	if err != nil {
-		return fmt.Errorf("failed to push: %w", err)
+		return history.Range{}, fmt.Errorf("failed to get tag %s: %w", c.currentTag, err)
 	}
 
-	return nil
+	historyRange.Stop = ref.Hash()
+
+	// annotated tags point to a tag object instead of the commit.
+	if tagObject, err := c.repo.TagObject(ref.Hash()); err == nil {
+		commit, err := tagObject.Commit()
+		if err != nil {
+			return history.Range{}, fmt.Errorf("failed to get commit of tag %s: %w", c.currentTag, err)
+		}
+
+		historyRange.Stop = commit.Hash
+	}
+
+	return historyRange, nil
 }
 
-func (c *Project) getGitTag(version string) string {
-	return strings.NewReplacer("{project}", c.name, "{version}", version).Replace(c.conf.GitTagPattern)
+// DetectRelease detects the next version and the changelog of the project.
+// The commits are the unreleased commits of the project, newest first. See HistoryRange.
+func (c *Project) DetectRelease(commits []*object.Commit) (semver.Version, *changelog.Changelog, error) {
+	changelogEntries, bump, releaseAs, err := c.collectChanges(commits)
+	if err != nil {
+		return semver.Version{}, nil, err
+	}
+
+	if version, ok := c.conf.ReleaseAs[c.name]; ok {
+		releaseAs = version
+	}
+
+	if releaseAs != nil && !releaseAs.GreaterThan(c.currentVersion) {
+		return semver.Version{}, nil, fmt.Errorf("%s: %s <= %s: %w", c.name, releaseAs, c.currentVersion, ErrReleaseAsNotGreater)
+	}
+
+	if bump == cc.UnknownVersion && releaseAs == nil {
+		return *c.currentVersion, changelogEntries, nil
+	}
+
+	version := utils.IncrementSemVerVersion(c.currentVersion, bump, c.config.InitialDevelopment)
+
+	switch {
+	case releaseAs != nil:
+		version = *releaseAs
+	case c.currentTag == "" && c.initialVersion != nil:
+		version = *c.initialVersion
+	}
+
+	changelogEntries.SetNewVersion(version.String())
+	c.logger.Info().Str("version", version.String()).Msg("commits detected")
+
+	return version, changelogEntries, nil
 }
 
+// collectChanges returns the changelog of the commits, the highest version bump and the highest
+// version of the Release-As footers. The new version of the changelog is not set.
+//
 //nolint:cyclop
-func (c *Project) DetectRelease() (semver.Version, *changelog.Changelog, error) {
-	repoLogs, err := c.repo.Log(&git.LogOptions{
-		PathFilter: func(s string) bool {
-			return strings.HasPrefix(s, c.projectPath)
-		},
-	})
+func (c *Project) collectChanges(commits []*object.Commit) (*changelog.Changelog, cc.VersionBump, *semver.Version, error) {
+	changelogEntries := changelog.New()
+
+	renderer, err := changelog.NewRenderer(c.config.Changelog.Format)
 	if err != nil {
-		return semver.Version{}, nil, fmt.Errorf("failed to get log: %w", err)
+		return nil, cc.UnknownVersion, nil, fmt.Errorf("failed to create changelog renderer: %w", err)
 	}
 
-	changelogEntries := changelog.New()
-	changelogEntries.SetOldVersion(c.currentVersion.String())
+	changelogEntries.SetRenderer(renderer)
+
+	// without a previous tag, there is nothing to compare with.
+	if c.currentTag != "" {
+		changelogEntries.SetOldVersion(c.currentVersion.String())
+	}
 
 	if remote, err := c.repo.Remote("origin"); err == nil {
 		changelogEntries.SetRemote(remote.Config().URLs[0])
 	}
 
-	bump := cc.UnknownVersion
+	changelogEntries.SetAuthors(c.config.Changelog.Authors)
+	changelogEntries.SetContributors(c.config.Changelog.Contributors)
+	changelogEntries.SetIssuesList(c.config.Changelog.IssuesList)
+	changelogEntries.SetGroupByScope(c.config.Changelog.GroupByScope)
+	changelogEntries.SetSort(c.config.Changelog.Sort)
 
-	tagCommitHash := ""
+	if c.config.Changelog.DateFormat != "" {
+		changelogEntries.SetDateFormat(c.config.Changelog.DateFormat)
+	}
 
-	tag, err := c.repo.Tag(c.getGitTag(c.currentVersion.String()))
-	if err == nil {
-		tagCommitHash = tag.Hash().String()
+	releaseDate, err := c.releaseDate()
+	if err != nil {
+		return nil, cc.UnknownVersion, nil, fmt.Errorf("failed to get release date: %w", err)
 	}
 
-	for log, err := repoLogs.Next(); err == nil; _, err = repoLogs.Next() {
-		commitHash := log.Hash.String()
-		if tagCommitHash == commitHash {
-			break
+	changelogEntries.SetDate(releaseDate)
+
+	for _, issuePattern := range c.config.Changelog.Issues {
+		if issuePattern.Pattern == nil {
+			continue
 		}
 
-		// parse only the first line of the message
-		log.Message, _, _ = strings.Cut(log.Message, "\n")
+		changelogEntries.AddIssuePattern(changelog.IssuePattern{Regexp: issuePattern.Pattern.Regexp, URL: issuePattern.URL})
+	}
+
+	if c.config.Changelog.UsernamesFile != "" {
+		usernames, err := c.readUsernames()
+		if err != nil {
+			return nil, cc.UnknownVersion, nil, fmt.Errorf("failed to read usernames: %w", err)
+		}
+
+		changelogEntries.SetUsernames(usernames)
+	}
+
+	bump := cc.UnknownVersion
+
+	var releaseAs *semver.Version
+
+	for _, commit := range c.dropReverts(commits) {
+		for _, commitMessage := range c.commitMessages(commit) {
+			message, _ := c.parseCommitMessage([]byte(commitMessage))
 
-		commitVersionBump, _ := c.parseCommitMessage([]byte(log.Message))
-		commitHash = commitHash[:7]
+			if reason := c.excludeReason(commit, commitMessage, message); reason != "" {
+				c.logger.Info().Str("message", firstLine(commitMessage)).Str("reason", reason).Msg("SKIP excluded")
 
-		switch commitVersionBump {
-		case cc.MajorVersion:
-			bump = cc.MajorVersion
+				continue
+			}
 
-			changelogEntries.AddBreaking(log.Message, commitHash)
-			c.logger.Info().Str("message", log.Message).Msg("MAJOR")
-		case cc.MinorVersion:
-			if bump != cc.MajorVersion {
-				bump = cc.MinorVersion
+			if version := c.parseReleaseAs(message); version != nil && (releaseAs == nil || version.GreaterThan(releaseAs)) {
+				releaseAs = version
 			}
 
-			changelogEntries.AddFeature(log.Message, commitHash)
-			c.logger.Info().Str("message", log.Message).Msg("MINOR")
-		case cc.PatchVersion:
-			if bump == cc.UnknownVersion {
-				bump = cc.PatchVersion
+			commitVersionBump := cc.UnknownVersion
+			if message != nil {
+				commitVersionBump = message.VersionBump(cc.DefaultStrategy)
 			}
 
-			changelogEntries.AddFix(log.Message, commitHash)
-			c.logger.Info().Str("message", log.Message).Msg("PATCH")
-		case cc.UnknownVersion:
-			c.logger.Info().Str("message", log.Message).Msg("SKIP")
+			// only the first line of the message is used in the changelog
+			entry := changelogEntry(commit, commitMessage, message)
+			subject := entry.Message
+
+			switch commitVersionBump {
+			case cc.MajorVersion:
+				bump = cc.MajorVersion
+
+				changelogEntries.Add(changelog.SectionBreaking, entry)
+				c.logger.Info().Str("message", subject).Msg("MAJOR")
+			case cc.MinorVersion:
+				if bump != cc.MajorVersion {
+					bump = cc.MinorVersion
+				}
+
+				changelogEntries.Add(changelog.SectionFeatures, entry)
+				c.logger.Info().Str("message", subject).Msg("MINOR")
+			case cc.PatchVersion:
+				if bump == cc.UnknownVersion {
+					bump = cc.PatchVersion
+				}
+
+				changelogEntries.Add(changelog.SectionFixes, entry)
+				c.logger.Info().Str("message", subject).Msg("PATCH")
+			case cc.UnknownVersion:
+				c.logger.Info().Str("message", subject).Msg("SKIP")
+			}
 		}
 	}
 
-	if bump == cc.UnknownVersion {
-		return *c.currentVersion, changelogEntries, nil
-	}
+	return changelogEntries, bump, releaseAs, nil
+}
 
-	version := utils.IncrementSemVerVersion(c.currentVersion, bump)
+// parseCommitMessage parses the commit message as conventional commit.
+// In best effort mode, a partial message is returned if at least the header is valid.
+func (c *Project) parseCommitMessage(commitMessage []byte) (*cc.ConventionalCommit, error) {
+	message, err := c.commitParser.Parse(commitMessage)
+	if message == nil || !message.Ok() {
+		return nil, fmt.Errorf("failed to parse commit message: %w", err)
+	}
 
-	changelogEntries.SetNewVersion(version.String())
-	c.logger.Info().Str("version", version.String()).Msg("commits detected")
+	conventionalCommit, ok := message.(*cc.ConventionalCommit)
+	if !ok {
+		return nil, fmt.Errorf("unexpected commit message type %T", message)
+	}
 
-	return version, changelogEntries, nil
+	return conventionalCommit, nil
 }
 
-func (c *Project) parseCommitMessage(commitMessage []byte) (cc.VersionBump, error) {
-	message, err := c.commitParser.Parse(commitMessage)
-	if err != nil {
-		return cc.UnknownVersion, fmt.Errorf("failed to parse commit message: %w", err)
+// parseReleaseAs returns the version of the Release-As footer, if present.
+func (c *Project) parseReleaseAs(message *cc.ConventionalCommit) *semver.Version {
+	if message == nil {
+		return nil
 	}
 
-	return message.VersionBump(cc.DefaultStrategy), nil
+	for _, value := range message.Footers["release-as"] {
+		version, err := semver.NewVersion(strings.TrimSpace(value))
+		if err != nil {
+			c.logger.Warn().Err(err).Str("value", value).Msg("ignoring invalid Release-As footer")
+
+			continue
+		}
+
+		return version
+	}
+
+	return nil
 }
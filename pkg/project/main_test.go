@@ -0,0 +1,395 @@
+package project
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/tag"
+	cc "github.com/leodido/go-conventionalcommits"
+	"github.com/leodido/go-conventionalcommits/parser"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommitToRepositoryDate(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	local, _ := newTestClone(t, remote)
+
+	project := newTestProject(t)
+	project.repo = local
+
+	date := time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC)
+
+	changes := changelog.New()
+	changes.AddFix("fix: it", "1234567")
+	changes.SetDate(date)
+
+	commit, err := project.commitToRepository(*semver.New(1, 2, 3, "", ""), changes)
+	require.NoError(t, err)
+
+	releaseCommit, err := local.CommitObject(commit)
+	require.NoError(t, err)
+
+	assert.True(t, date.Equal(releaseCommit.Author.When))
+	assert.True(t, date.Equal(releaseCommit.Committer.When))
+	assert.Equal(t, "test@example.com", releaseCommit.Committer.Email)
+}
+
+//nolint:paralleltest // uses t.Setenv
+func TestCommitToRepositoryDateBeforeHead(t *testing.T) {
+	t.Setenv("SOURCE_DATE_EPOCH", "1577836800")
+
+	remote := newTestRemote(t)
+	local, fs := newTestClone(t, remote)
+	head := commitFile(t, local, fs, "charts/test/values.yaml", "image: test\n")
+
+	project := newTestProject(t)
+	project.repo = local
+
+	date, err := project.releaseDate()
+	require.NoError(t, err)
+
+	changes := changelog.New()
+	changes.AddFix("fix: it", "1234567")
+	changes.SetDate(date)
+
+	commit, err := project.commitToRepository(*semver.New(1, 2, 3, "", ""), changes)
+	require.NoError(t, err)
+
+	parentCommit, err := local.CommitObject(head)
+	require.NoError(t, err)
+
+	releaseCommit, err := local.CommitObject(commit)
+	require.NoError(t, err)
+
+	// the release commit is not older than its parent and the changelog carries the date of the release commit.
+	assert.True(t, parentCommit.Committer.When.Equal(releaseCommit.Committer.When))
+	assert.True(t, parentCommit.Committer.When.Equal(releaseCommit.Author.When))
+	assert.True(t, releaseCommit.Committer.When.Equal(changes.Date()))
+
+	content, err := util.ReadFile(fs, "charts/test/"+changes.FileName())
+	require.NoError(t, err)
+	assert.Contains(t, string(content), releaseCommit.Committer.When.Format(changelog.DefaultDateFormat))
+}
+
+// createTestTags creates a commit with the tags in the repository of the project.
+func createTestTags(t *testing.T, project *Project, tags ...string) {
+	t.Helper()
+
+	worktree, err := project.repo.Worktree()
+	require.NoError(t, err)
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com"}
+
+	commit, err := worktree.Commit("chore: init", &git.CommitOptions{Author: signature, AllowEmptyCommits: true})
+	require.NoError(t, err)
+
+	for _, tagName := range tags {
+		_, err = project.repo.CreateTag(tagName, commit, nil)
+		require.NoError(t, err)
+	}
+}
+
+func TestReadCurrentVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name        string
+		tags        []string
+		expected    string
+		expectedTag string
+	}{
+		{"no tags", nil, "0.0.0", ""},
+		{"latest", []string{"test/v1.2.3", "test/v1.10.0", "other/v2.0.0"}, "1.10.0", "test/v1.10.0"},
+		{"invalid version skipped", []string{"test/v1.2.3", "test/v01.2.4"}, "1.2.3", "test/v1.2.3"},
+		{"legacy tag older", []string{"test-1.2.0", "test/v1.2.3"}, "1.2.3", "test/v1.2.3"},
+		{"legacy tag newer", []string{"test/v1.2.3", "test-1.3.0"}, "1.3.0", "test-1.3.0"},
+		{"legacy tag of other project", []string{"test/v1.2.3", "other-2.0.0"}, "1.2.3", "test/v1.2.3"},
+		{"invalid legacy version skipped", []string{"test/v1.2.3", "test-01.3.0"}, "1.2.3", "test/v1.2.3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			legacyTagPattern, err := tag.New("{project}-{version}", "", "test")
+			require.NoError(t, err)
+
+			project := newTestProject(t)
+			project.currentVersion = semver.New(0, 0, 0, "", "")
+			project.legacyTagPatterns = []tag.Pattern{legacyTagPattern}
+
+			createTestTags(t, project, tc.tags...)
+
+			require.NoError(t, project.readCurrentVersion(nil))
+			assert.Equal(t, tc.expected, project.CurrentVersion())
+			assert.Equal(t, tc.expectedTag, project.currentTag)
+		})
+	}
+}
+
+func TestNewInvalidLegacyTagPattern(t *testing.T) {
+	t.Parallel()
+
+	conf := config.New()
+	conf.GitTagLegacyPatterns = []string{"{project}-{major}.{minor}"}
+
+	_, err := New(zerolog.Nop(), conf, newTestProject(t).repo, nil, "test")
+	require.ErrorContains(t, err, "failed to parse legacy git tag pattern")
+	require.ErrorIs(t, err, tag.ErrMissingVersionPlaceholder)
+}
+
+func TestReadProjectConfig(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		tagPattern string
+		content    string
+		err        error
+	}{
+		{"recovery", "{project}/{version}", "recovery:\n  onPublishFailure: record\n", nil},
+		{"publish before push", "{project}/{version}", "recovery:\n  publishBeforePush: true\n  onPublishFailure: none\n", nil},
+		{"invalid recovery", "{project}/{version}", "recovery:\n  onPublishFailure: retry\n", ErrInvalidRecovery},
+		{"conflicting recovery", "{project}/{version}", "recovery:\n  publishBeforePush: true\n  onPublishFailure: record\n", ErrRecoveryConflict},
+		{"publish before push with sha", "{project}/{version}+{shortSha}", "recovery:\n  publishBeforePush: true\n", ErrPublishBeforePushSha},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newTestProject(t)
+			project.conf = &config.Config{ConfigFilePath: ".releaser.yaml"}
+
+			tagPattern, err := tag.New(tc.tagPattern, "", "test")
+			require.NoError(t, err)
+
+			project.tagPattern = tagPattern
+
+			worktree, err := project.repo.Worktree()
+			require.NoError(t, err)
+			require.NoError(t, util.WriteFile(worktree.Filesystem, "charts/test/.releaser.yaml", []byte(tc.content), 0o644))
+
+			err = project.readProjectConfig()
+			if tc.err == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+}
+
+// newDetectTestProject returns the test project with the current version 1.2.2 and a HEAD commit for the release date.
+func newDetectTestProject(t *testing.T) *Project {
+	t.Helper()
+
+	project := newTestProject(t)
+	project.conf = config.New()
+	project.currentTag = "test/v1.2.2"
+
+	commitParser := parser.NewMachine(parser.WithTypes(cc.TypesConventional))
+	commitParser.WithBestEffort()
+	project.commitParser = commitParser
+
+	createTestTags(t, project)
+
+	return project
+}
+
+// testCommits returns the commits of the messages, newest first.
+func testCommits(messages ...string) []*object.Commit {
+	commits := make([]*object.Commit, len(messages))
+
+	for i, message := range messages {
+		commits[i] = testCommit(message)
+	}
+
+	return commits
+}
+
+func TestDetectReleaseAs(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name      string
+		messages  []string
+		releaseAs string
+		expected  string
+		err       error
+	}{
+		{"footer", []string{"fix: x\n\nRelease-As: 2.0.0"}, "", "2.0.0", nil},
+		{"highest footer", []string{"fix: x\n\nRelease-As: 1.5.0", "fix: y\n\nRelease-As: 3.0.0"}, "", "3.0.0", nil},
+		{"without bump", []string{"chore: x\n\nRelease-As: 2.0.0"}, "", "2.0.0", nil},
+		{"invalid footer", []string{"fix: x\n\nRelease-As: next"}, "", "1.2.3", nil},
+		{"flag overrides footer", []string{"fix: x\n\nRelease-As: 3.0.0"}, "2.5.0", "2.5.0", nil},
+		{"flag without commits", nil, "2.5.0", "2.5.0", nil},
+		{"footer not greater", []string{"fix: x\n\nRelease-As: 1.2.2"}, "", "", ErrReleaseAsNotGreater},
+		{"flag not greater", []string{"feat: x"}, "1.0.0", "", ErrReleaseAsNotGreater},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newDetectTestProject(t)
+
+			if tc.releaseAs != "" {
+				project.conf.ReleaseAs["test"] = semver.MustParse(tc.releaseAs)
+			}
+
+			// --release-as of other projects is ignored.
+			project.conf.ReleaseAs["other"] = semver.MustParse("9.0.0")
+
+			version, _, err := project.DetectRelease(testCommits(tc.messages...))
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, version.String())
+		})
+	}
+}
+
+func TestReadInitialVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		config   Config
+		file     string
+		expected string
+		err      string
+	}{
+		{"none", Config{}, "", "", ""},
+		{"config", Config{InitialVersion: "1.0.0"}, "", "1.0.0", ""},
+		{"file", Config{InitialVersion: "1.0.0", InitialVersionFile: "Chart.yaml"}, "version: v2.1.0\n", "2.1.0", ""},
+		{"file without version", Config{InitialVersionFile: "Chart.yaml"}, "name: test\n", "", ""},
+		{"missing file", Config{InitialVersionFile: "missing.yaml"}, "", "", "failed to read missing.yaml"},
+		{"invalid version", Config{InitialVersion: "one"}, "", "", `failed to parse initial version "one"`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newTestProject(t)
+			project.config = tc.config
+
+			worktree, err := project.repo.Worktree()
+			require.NoError(t, err)
+			require.NoError(t, util.WriteFile(worktree.Filesystem, "charts/test/Chart.yaml", []byte(tc.file), 0o644))
+
+			err = project.readInitialVersion()
+			if tc.err != "" {
+				require.ErrorContains(t, err, tc.err)
+
+				return
+			}
+
+			require.NoError(t, err)
+
+			if tc.expected == "" {
+				assert.Nil(t, project.initialVersion)
+			} else {
+				assert.Equal(t, tc.expected, project.initialVersion.String())
+			}
+		})
+	}
+}
+
+func TestDetectReleaseInitialVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name       string
+		currentTag string
+		messages   []string
+		releaseAs  string
+		expected   string
+	}{
+		{"computed bump ignored", "", []string{"feat: x"}, "", "1.0.0"},
+		{"breaking change ignored", "", []string{"feat!: x"}, "", "1.0.0"},
+		{"no releasable commits", "", []string{"chore: x"}, "", "0.0.0"},
+		{"release-as", "", []string{"feat: x"}, "2.0.0", "2.0.0"},
+		{"existing tag", "test/v0.0.0", []string{"feat: x"}, "", "0.1.0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newDetectTestProject(t)
+			project.currentTag = tc.currentTag
+			project.currentVersion = semver.New(0, 0, 0, "", "")
+			project.initialVersion = semver.New(1, 0, 0, "", "")
+
+			if tc.releaseAs != "" {
+				project.conf.ReleaseAs["test"] = semver.MustParse(tc.releaseAs)
+			}
+
+			version, _, err := project.DetectRelease(testCommits(tc.messages...))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, version.String())
+		})
+	}
+}
+
+func TestDetectReleaseBreakingChange(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name               string
+		current            string
+		initialDevelopment bool
+		message            string
+		expected           string
+		breaking           bool
+	}{
+		{"footer", "1.2.2", false, "fix: x\n\nBREAKING CHANGE: y", "2.0.0", true},
+		{"footer with hyphen", "1.2.2", false, "feat: x\n\nBREAKING-CHANGE: y", "2.0.0", true},
+		{"footer after body", "1.2.2", false, "fix: x\n\nsome details\n\nBREAKING CHANGE: y\nRefs: #1", "2.0.0", true},
+		{"exclamation mark", "1.2.2", false, "fix!: x", "2.0.0", true},
+		{"mentioned in body", "1.2.2", false, "fix: x\n\nthis is no BREAKING CHANGE", "1.2.3", false},
+		{"initial development", "0.4.2", true, "fix: x\n\nBREAKING CHANGE: y", "0.5.0", true},
+		{"initial development feature", "0.4.2", true, "feat: x", "0.4.3", false},
+		{"initial development after 1.0.0", "1.2.2", true, "fix: x\n\nBREAKING CHANGE: y", "2.0.0", true},
+		{"graduation", "0.4.2", true, "fix: x\n\nBREAKING CHANGE: y\nRelease-As: 1.0.0", "1.0.0", true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			project := newDetectTestProject(t)
+			project.currentVersion = semver.MustParse(tc.current)
+			project.config.InitialDevelopment = tc.initialDevelopment
+
+			version, changes, err := project.DetectRelease(testCommits(tc.message))
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, version.String())
+
+			assert.Equal(t, tc.breaking, strings.Contains(changes.String(), "BREAKING CHANGES"))
+		})
+	}
+}
+
+func TestReadCurrentVersionBaselineTag(t *testing.T) {
+	t.Parallel()
+
+	tagPattern, err := tag.New("{project}/{version}", "", "test")
+	require.NoError(t, err)
+
+	project := newTestProject(t)
+	project.currentVersion = semver.New(0, 0, 0, "", "")
+	project.tagPattern = tagPattern
+
+	// the default pattern without version prefix keeps the current version of tags created by earlier releases.
+	createTestTags(t, project, "test/v1.2.3", "test/1.3")
+
+	require.NoError(t, project.readCurrentVersion(nil))
+	assert.Equal(t, "1.3.0", project.CurrentVersion())
+	assert.Equal(t, "test/1.3", project.currentTag)
+}
@@ -0,0 +1,97 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/history"
+)
+
+// Publish runs only the publishNewVersion hook for an existing tag of the project, e.g. after a registry outage.
+// The tag must be checked out. The previous version is reconstructed from the tags. The changelog of the release
+// is taken from the changelog file at the tag. If the file does not contain the release, the changelog is
+// reconstructed from the commits between the previous tag and the tag. Release-As footers and --release-as are ignored.
+// If the tag does not belong to the project, ErrTagNotOfProject is returned.
+func (c *Project) Publish(ctx context.Context, tagName string) error {
+	version, ok, err := c.parseTag(tagName)
+	if err != nil {
+		return fmt.Errorf("failed to parse tag %s: %w", tagName, err)
+	}
+
+	if !ok {
+		return fmt.Errorf("%s: %w", tagName, ErrTagNotOfProject)
+	}
+
+	c.currentTag = ""
+	c.currentVersion = semver.New(0, 0, 0, "", "")
+
+	if err = c.readCurrentVersion(version); err != nil {
+		return fmt.Errorf("failed to read previous version: %w", err)
+	}
+
+	historyRange, err := c.HistoryRange()
+	if err != nil {
+		return fmt.Errorf("failed to get history range: %w", err)
+	}
+
+	commits, err := history.Collect(c.repo, []history.Range{historyRange}, history.Options{
+		FirstParent: c.conf.MergeCommits == config.MergeCommitsFirstParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect commits: %w", err)
+	}
+
+	changelogEntries, _, _, err := c.collectChanges(commits[0])
+	if err != nil {
+		return fmt.Errorf("failed to reconstruct changelog: %w", err)
+	}
+
+	changelogEntries.SetNewVersion(version.String())
+
+	data := c.templateData(version, changelogEntries)
+	data["tagName"] = tagName
+
+	release, err := c.readRelease(changelogEntries)
+	if err != nil {
+		return err
+	}
+
+	if release != "" {
+		data["changelog"] = release
+	} else {
+		c.logger.Warn().Str("tag", tagName).Msg("release not found in changelog file, using the changelog of the commits")
+	}
+
+	c.logger.Info().Str("tag", tagName).Msg("publishing")
+
+	return c.runHook(ctx, stagePublish, c.config.Commands.Publish, data)
+}
+
+// readRelease returns the release of the new version of the changelog from the changelog file,
+// or an empty string if the file or the release does not exist.
+func (c *Project) readRelease(changelogEntries *changelog.Changelog) (string, error) {
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	data, err := util.ReadFile(worktree.Filesystem, filepath.Join(c.projectPath, changelogEntries.FileName()))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read changelog: %w", err)
+	}
+
+	release, _ := changelogEntries.Extract(data)
+
+	return release, nil
+}
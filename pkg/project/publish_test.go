@@ -0,0 +1,104 @@
+package project
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	cc "github.com/leodido/go-conventionalcommits"
+	"github.com/leodido/go-conventionalcommits/parser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+
+	// release 1.2.3 with a failing publish command.
+	project := newTestRecoveryProject(t, remote, ConfigCommands{Publish: Hook{Command: "exit 1"}}, ConfigRecovery{})
+
+	head, err := project.repo.Head()
+	require.NoError(t, err)
+
+	_, err = project.repo.CreateTag("test/v1.2.2", head.Hash(), nil)
+	require.NoError(t, err)
+
+	worktree, err := project.repo.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, util.WriteFile(worktree.Filesystem, "charts/test/values.yaml", []byte("fixed: true\n"), 0o644))
+
+	_, err = worktree.Add("charts/test/values.yaml")
+	require.NoError(t, err)
+
+	_, err = worktree.Commit("fix: the bug", &git.CommitOptions{})
+	require.NoError(t, err)
+
+	require.ErrorContains(t, releaseTestProject(t, project), "publishNewVersion hook failed")
+
+	// publish the tag afterward. The changelog file of the test release lacks the version,
+	// so the changelog is reconstructed from the commits.
+	log := filepath.Join(t.TempDir(), "hooks.log")
+	project.config.Commands.Publish = Hook{
+		Command: `echo "{{ .currentVersion }} {{ .nextVersion }} {{ .bump }} {{ .tagName }} $(grep -c 'the bug' "$SEMREL_RELEASE_NOTES_FILE")" >> ` + log,
+	}
+	project.conf.MergeCommits = "include"
+	project.commitParser = parser.NewMachine(parser.WithTypes(cc.TypesConventional))
+
+	require.NoError(t, project.Publish(context.Background(), "test/v1.2.3"))
+
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.2 1.2.3 patch test/v1.2.3 1\n", string(content))
+
+	require.ErrorIs(t, project.Publish(context.Background(), "other/v1.2.3"), ErrTagNotOfProject)
+}
+
+func TestPublishChangelogFile(t *testing.T) {
+	t.Parallel()
+
+	log := filepath.Join(t.TempDir(), "release-notes.md")
+
+	project := newTestRecoveryProject(t, newTestRemote(t), ConfigCommands{
+		Publish: Hook{Command: `cat "$SEMREL_RELEASE_NOTES_FILE" > ` + log},
+	}, ConfigRecovery{})
+
+	head, err := project.repo.Head()
+	require.NoError(t, err)
+
+	_, err = project.repo.CreateTag("test/v1.2.2", head.Hash(), nil)
+	require.NoError(t, err)
+
+	worktree, err := project.repo.Worktree()
+	require.NoError(t, err)
+
+	release := "### 1.2.3 (2024-05-06)\n\n### Bug Fixes\n\n* edited after the release\n\n"
+	changelogFile := "# Changelog\n\n<!-- INSERT COMMENT -->\n\n" + release + "### 1.2.2 (2024-05-05)\n\n### Bug Fixes\n\n* older\n\n"
+
+	require.NoError(t, util.WriteFile(worktree.Filesystem, "charts/test/CHANGELOG.md", []byte(changelogFile), 0o644))
+
+	_, err = worktree.Add("charts/test/CHANGELOG.md")
+	require.NoError(t, err)
+
+	commit, err := worktree.Commit("fix: the bug", &git.CommitOptions{})
+	require.NoError(t, err)
+
+	_, err = project.repo.CreateTag("test/v1.2.3", commit, nil)
+	require.NoError(t, err)
+
+	// --release-as is ignored, although it is not greater than the previous version.
+	project.conf.ReleaseAs = map[string]*semver.Version{"test": semver.New(1, 0, 0, "", "")}
+	project.commitParser = parser.NewMachine(parser.WithTypes(cc.TypesConventional))
+
+	require.NoError(t, project.Publish(context.Background(), "test/v1.2.3"))
+
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, release, string(content))
+}
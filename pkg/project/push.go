@@ -0,0 +1,275 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/jkroepke/semantic-releaser/pkg/history"
+)
+
+// push pushes the release commit and its tag. If the remote branch moved in the meantime,
+// the release commit is reapplied onto the remote branch and the push is retried.
+// The pushed commit and tag name are returned, since the tag name may contain the hash of the reapplied commit.
+func (c *Project) push(ctx context.Context, version semver.Version, commit plumbing.Hash) (plumbing.Hash, string, error) {
+	head, err := c.repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	tagName := c.getGitTag(version, commit.String())
+
+	for attempt := 1; ; attempt++ {
+		refSpecs := []config.RefSpec{refSpec(plumbing.NewTagReferenceName(tagName))}
+
+		// on a detached HEAD, the release commit is pushed by its tag only.
+		if head.Name().IsBranch() {
+			refSpecs = append(refSpecs, refSpec(head.Name()))
+		}
+
+		err = c.repo.PushContext(ctx, &git.PushOptions{RemoteName: git.DefaultRemoteName, RefSpecs: refSpecs})
+		if err == nil {
+			return commit, tagName, nil
+		}
+
+		if attempt >= c.conf.GitPushAttempts || !head.Name().IsBranch() {
+			return plumbing.ZeroHash, "", fmt.Errorf("failed to push: %w", err)
+		}
+
+		moved, movedErr := c.remoteMoved(ctx, head.Name(), commit)
+		if movedErr != nil {
+			return plumbing.ZeroHash, "", fmt.Errorf("failed to push: %w", errors.Join(err, movedErr))
+		}
+
+		if !moved {
+			return plumbing.ZeroHash, "", fmt.Errorf("failed to push: %w", err)
+		}
+
+		c.logger.Warn().Err(err).Int("attempt", attempt).Msg("remote branch moved, reapplying the release commit")
+
+		commit, tagName, err = c.reapplyReleaseCommit(ctx, version, head.Name(), commit, tagName)
+		if err != nil {
+			return plumbing.ZeroHash, "", fmt.Errorf("failed to reapply the release commit: %w", err)
+		}
+	}
+}
+
+// reapplyReleaseCommit fetches the remote branch and recreates the release commit and its tag on top of it.
+// The release commit changes files inside the project directory only. If the remote branch contains new
+// commits of the project, the computed version may be outdated and ErrReleaseOutdated is returned.
+func (c *Project) reapplyReleaseCommit(
+	ctx context.Context, version semver.Version, branch plumbing.ReferenceName, commit plumbing.Hash, tagName string,
+) (plumbing.Hash, string, error) {
+	err := c.repo.FetchContext(ctx, &git.FetchOptions{RemoteName: git.DefaultRemoteName})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to fetch: %w", err)
+	}
+
+	remoteRef, err := c.repo.Reference(plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch.Short()), true)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to get remote branch: %w", err)
+	}
+
+	releaseCommit, err := c.repo.CommitObject(commit)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to get release commit: %w", err)
+	}
+
+	projectPath := filepath.ToSlash(c.projectPath)
+
+	commits, err := history.Collect(c.repo,
+		[]history.Range{{Path: projectPath, Stop: parentHash(releaseCommit)}},
+		history.Options{Start: remoteRef.Hash()},
+	)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to collect remote commits: %w", err)
+	}
+
+	if len(commits[0]) > 0 {
+		return plumbing.ZeroHash, "", fmt.Errorf("%d new commits: %w", len(commits[0]), ErrReleaseOutdated)
+	}
+
+	treeHash, err := c.rebaseTree(releaseCommit, remoteRef.Hash(), projectPath)
+	if err != nil {
+		return plumbing.ZeroHash, "", err
+	}
+
+	obj := c.repo.Storer.NewEncodedObject()
+
+	if err = (&object.Commit{
+		Author:       releaseCommit.Author,
+		Committer:    releaseCommit.Committer,
+		Message:      releaseCommit.Message,
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{remoteRef.Hash()},
+	}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	newCommit, err := c.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// moves the branch and updates the files changed on the remote, local changes are kept.
+	if err = worktree.Reset(&git.ResetOptions{Commit: newCommit, Mode: git.MergeReset}); err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to reset worktree: %w", err)
+	}
+
+	if err = c.repo.DeleteTag(tagName); err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to delete tag %s: %w", tagName, err)
+	}
+
+	newTagName := c.getGitTag(version, newCommit.String())
+
+	if _, err = c.repo.CreateTag(newTagName, newCommit, nil); err != nil {
+		return plumbing.ZeroHash, "", fmt.Errorf("failed to create tag %s: %w", newTagName, err)
+	}
+
+	return newCommit, newTagName, nil
+}
+
+// rebaseTree returns the tree of the remote commit, with the project directory taken from the release commit.
+func (c *Project) rebaseTree(releaseCommit *object.Commit, remoteCommit plumbing.Hash, projectPath string) (plumbing.Hash, error) {
+	releaseTree, err := releaseCommit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get tree of release commit: %w", err)
+	}
+
+	projectTree, err := releaseTree.Tree(projectPath)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get project tree of release commit: %w", err)
+	}
+
+	remoteTree, err := object.GetCommit(c.repo.Storer, remoteCommit)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get remote commit: %w", err)
+	}
+
+	tree, err := remoteTree.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to get tree of remote commit: %w", err)
+	}
+
+	return replaceTree(c.repo.Storer, tree, strings.Split(projectPath, "/"), projectTree.Hash)
+}
+
+// replaceTree stores a copy of the tree, with the subtree at path replaced, and returns its hash.
+// Missing directories of the path are created, e.g. if the project directory was removed on the remote.
+func replaceTree(s storer.EncodedObjectStorer, tree *object.Tree, path []string, subtree plumbing.Hash) (plumbing.Hash, error) {
+	entries := slices.Clone(tree.Entries)
+
+	child := &object.Tree{}
+
+	i := slices.IndexFunc(entries, func(entry object.TreeEntry) bool { return entry.Name == path[0] })
+	if i < 0 || entries[i].Mode != filemode.Dir {
+		entries = slices.DeleteFunc(entries, func(entry object.TreeEntry) bool { return entry.Name == path[0] })
+		entries = append(entries, object.TreeEntry{Name: path[0], Mode: filemode.Dir})
+
+		// git orders the entries by name, with a trailing slash for directories.
+		slices.SortFunc(entries, func(a, b object.TreeEntry) int { return strings.Compare(treeEntryKey(a), treeEntryKey(b)) })
+
+		i = slices.IndexFunc(entries, func(entry object.TreeEntry) bool { return entry.Name == path[0] })
+	} else if len(path) > 1 {
+		var err error
+
+		if child, err = object.GetTree(s, entries[i].Hash); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to get tree %s: %w", path[0], err)
+		}
+	}
+
+	entries[i].Hash = subtree
+
+	if len(path) > 1 {
+		var err error
+
+		if entries[i].Hash, err = replaceTree(s, child, path[1:], subtree); err != nil {
+			return plumbing.ZeroHash, err
+		}
+	}
+
+	obj := s.NewEncodedObject()
+
+	if err := (&object.Tree{Entries: entries}).Encode(obj); err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to encode tree: %w", err)
+	}
+
+	hash, err := s.SetEncodedObject(obj)
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("failed to store tree: %w", err)
+	}
+
+	return hash, nil
+}
+
+// treeEntryKey returns the sort key of the tree entry.
+func treeEntryKey(entry object.TreeEntry) string {
+	if entry.Mode == filemode.Dir {
+		return entry.Name + "/"
+	}
+
+	return entry.Name
+}
+
+// remoteMoved reports whether the push of the release commit failed, because the remote branch moved.
+// go-git reports rejected updates by error messages only, therefore the remote branch is compared
+// with the parent of the release commit instead.
+func (c *Project) remoteMoved(ctx context.Context, branch plumbing.ReferenceName, commit plumbing.Hash) (bool, error) {
+	releaseCommit, err := c.repo.CommitObject(commit)
+	if err != nil {
+		return false, fmt.Errorf("failed to get release commit: %w", err)
+	}
+
+	remote, err := c.repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return false, fmt.Errorf("failed to get remote: %w", err)
+	}
+
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to list remote references: %w", err)
+	}
+
+	for _, ref := range refs {
+		if ref.Name() == branch {
+			return ref.Hash() != parentHash(releaseCommit) && ref.Hash() != commit, nil
+		}
+	}
+
+	// without a remote branch, the push failed for another reason.
+	return false, nil
+}
+
+// parentHash returns the first parent of the commit. For a root commit, the zero hash is returned.
+func parentHash(commit *object.Commit) plumbing.Hash {
+	if len(commit.ParentHashes) == 0 {
+		return plumbing.ZeroHash
+	}
+
+	return commit.ParentHashes[0]
+}
+
+// refSpec returns a refspec pushing the local reference to the remote reference of the same name.
+func refSpec(name plumbing.ReferenceName) config.RefSpec {
+	return config.RefSpec(name.String() + ":" + name.String())
+}
+
+// deleteRefSpec returns a refspec deleting the remote reference.
+func deleteRefSpec(name plumbing.ReferenceName) config.RefSpec {
+	return config.RefSpec(":" + name.String())
+}
@@ -0,0 +1,242 @@
+package project
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestClone clones the remote into memory.
+func newTestClone(t *testing.T, remote string) (*git.Repository, billy.Filesystem) {
+	t.Helper()
+
+	fs := memfs.New()
+
+	repo, err := git.Clone(memory.NewStorage(), fs, &git.CloneOptions{URL: remote})
+	require.NoError(t, err)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@example.com"
+	require.NoError(t, repo.SetConfig(cfg))
+
+	return repo, fs
+}
+
+// commitFile commits the file and pushes the commit.
+func commitFile(t *testing.T, repo *git.Repository, fs billy.Filesystem, path, content string) plumbing.Hash {
+	t.Helper()
+
+	require.NoError(t, util.WriteFile(fs, path, []byte(content), 0o644))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	_, err = worktree.Add(path)
+	require.NoError(t, err)
+
+	hash, err := worktree.Commit("chore: update "+path, &git.CommitOptions{})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Push(&git.PushOptions{}))
+
+	return hash
+}
+
+// newTestRemote returns a bare repository with a project test.
+func newTestRemote(t *testing.T) string {
+	t.Helper()
+
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	fs := memfs.New()
+
+	repo, err := git.Init(memory.NewStorage(), fs)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: "origin", URLs: []string{remote}})
+	require.NoError(t, err)
+
+	require.NoError(t, util.WriteFile(fs, "charts/test/Chart.yaml", []byte("version: 1.2.2\n"), 0o644))
+	require.NoError(t, util.WriteFile(fs, "README.md", []byte("# test\n"), 0o644))
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	require.NoError(t, worktree.AddGlob("."))
+
+	signature := &object.Signature{Name: "test", Email: "test@example.com"}
+	_, err = worktree.Commit("chore: init", &git.CommitOptions{Author: signature})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Push(&git.PushOptions{}))
+
+	return remote
+}
+
+func TestPushRemoteMoved(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	local, _ := newTestClone(t, remote)
+	other, otherFS := newTestClone(t, remote)
+
+	otherCommit := commitFile(t, other, otherFS, "README.md", "# moved\n")
+
+	project := newTestProject(t)
+	project.repo = local
+	project.conf = &config.Config{GitPushAttempts: 2}
+
+	changes := changelog.New()
+	changes.AddFix("fix: it", "1234567")
+
+	version := *semver.New(1, 2, 3, "", "")
+
+	commit, err := project.commitToRepository(version, changes)
+	require.NoError(t, err)
+
+	_, tagName, err := project.push(context.Background(), version, commit)
+	require.NoError(t, err)
+	assert.Equal(t, "test/v1.2.3", tagName)
+
+	remoteRepo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+
+	tagRef, err := remoteRepo.Tag(tagName)
+	require.NoError(t, err)
+
+	branchRef, err := remoteRepo.Reference(plumbing.NewBranchReferenceName("master"), true)
+	require.NoError(t, err)
+	assert.Equal(t, branchRef.Hash(), tagRef.Hash())
+
+	releaseCommit, err := remoteRepo.CommitObject(branchRef.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, []plumbing.Hash{otherCommit}, releaseCommit.ParentHashes)
+
+	readme, err := releaseCommit.File("README.md")
+	require.NoError(t, err)
+
+	content, err := readme.Contents()
+	require.NoError(t, err)
+	assert.Equal(t, "# moved\n", content)
+
+	_, err = releaseCommit.File("charts/test/CHANGELOG.md")
+	require.NoError(t, err)
+}
+
+func TestPushReleaseOutdated(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	local, _ := newTestClone(t, remote)
+	other, otherFS := newTestClone(t, remote)
+
+	commitFile(t, other, otherFS, "charts/test/Chart.yaml", "version: 1.2.2\nname: test\n")
+
+	project := newTestProject(t)
+	project.repo = local
+	project.conf = &config.Config{GitPushAttempts: 2}
+
+	changes := changelog.New()
+	changes.AddFix("fix: it", "1234567")
+
+	version := *semver.New(1, 2, 3, "", "")
+
+	commit, err := project.commitToRepository(version, changes)
+	require.NoError(t, err)
+
+	_, _, err = project.push(context.Background(), version, commit)
+	require.ErrorIs(t, err, ErrReleaseOutdated)
+}
+
+func TestReplaceTree(t *testing.T) {
+	t.Parallel()
+
+	storage := memory.NewStorage()
+
+	storeTree := func(entries ...object.TreeEntry) plumbing.Hash {
+		t.Helper()
+
+		obj := storage.NewEncodedObject()
+		require.NoError(t, (&object.Tree{Entries: entries}).Encode(obj))
+
+		hash, err := storage.SetEncodedObject(obj)
+		require.NoError(t, err)
+
+		return hash
+	}
+
+	file := plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")
+	project := storeTree(object.TreeEntry{Name: "CHANGELOG.md", Mode: filemode.Regular, Hash: file})
+	other := storeTree(object.TreeEntry{Name: "Chart.yaml", Mode: filemode.Regular, Hash: file})
+
+	for _, tc := range []struct {
+		name    string
+		entries []object.TreeEntry
+	}{
+		{"existing project", []object.TreeEntry{
+			{Name: "README.md", Mode: filemode.Regular, Hash: file},
+			{Name: "charts", Mode: filemode.Dir, Hash: storeTree(
+				object.TreeEntry{Name: "other", Mode: filemode.Dir, Hash: other},
+				object.TreeEntry{Name: "test", Mode: filemode.Dir, Hash: other},
+			)},
+		}},
+		{"missing project", []object.TreeEntry{
+			{Name: "README.md", Mode: filemode.Regular, Hash: file},
+			{Name: "charts", Mode: filemode.Dir, Hash: storeTree(object.TreeEntry{Name: "other", Mode: filemode.Dir, Hash: other})},
+		}},
+		{"missing parent", []object.TreeEntry{
+			{Name: "README.md", Mode: filemode.Regular, Hash: file},
+			{Name: "charts.md", Mode: filemode.Regular, Hash: file},
+			{Name: "docs", Mode: filemode.Dir, Hash: other},
+		}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := object.GetTree(storage, storeTree(tc.entries...))
+			require.NoError(t, err)
+
+			hash, err := replaceTree(storage, tree, []string{"charts", "test"}, project)
+			require.NoError(t, err)
+
+			replaced, err := object.GetTree(storage, hash)
+			require.NoError(t, err)
+
+			projectTree, err := replaced.Tree("charts/test")
+			require.NoError(t, err)
+			assert.Equal(t, project, projectTree.Hash)
+
+			readme, err := replaced.FindEntry("README.md")
+			require.NoError(t, err)
+			assert.Equal(t, file, readme.Hash)
+
+			// the tree is stored in git order.
+			names := make([]string, 0, len(replaced.Entries))
+			for _, entry := range replaced.Entries {
+				names = append(names, treeEntryKey(entry))
+			}
+
+			assert.IsNonDecreasing(t, names)
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// unpublishedRefPrefix is the prefix of references recording pushed tags, which are not published yet.
+const unpublishedRefPrefix = "refs/releaser/unpublished/"
+
+// discardRelease deletes the local release tag and resets the branch to the parent of the release commit.
+// Local changes, which are not part of the release commit, are kept.
+func (c *Project) discardRelease(version semver.Version, commit plumbing.Hash, err error) error {
+	c.logger.Warn().Err(err).Msg("publishing failed, discarding the local release commit")
+
+	if tagErr := c.repo.DeleteTag(c.getGitTag(version, commit.String())); tagErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to delete tag: %w", tagErr))
+	}
+
+	releaseCommit, resetErr := c.repo.CommitObject(commit)
+	if resetErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to get release commit: %w", resetErr))
+	}
+
+	// a release commit without parent can not be discarded by a reset.
+	if releaseCommit.NumParents() == 0 {
+		return errors.Join(err, fmt.Errorf("failed to discard release commit %s: %w", commit, ErrRootReleaseCommit))
+	}
+
+	worktree, resetErr := c.repo.Worktree()
+	if resetErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to get worktree: %w", resetErr))
+	}
+
+	if resetErr = worktree.Reset(&git.ResetOptions{Commit: releaseCommit.ParentHashes[0], Mode: git.MergeReset}); resetErr != nil {
+		return errors.Join(err, fmt.Errorf("failed to reset worktree: %w", resetErr))
+	}
+
+	return err
+}
+
+// recoverPublish applies the configured recovery after publishNewVersion failed for a pushed tag.
+func (c *Project) recoverPublish(ctx context.Context, commit plumbing.Hash, tagName string, err error) error {
+	tagRef := plumbing.NewTagReferenceName(tagName)
+
+	switch c.config.Recovery.OnPublishFailure {
+	case RecoveryDeleteTag:
+		c.logger.Warn().Err(err).Str("tag", tagName).Msg("publishing failed, deleting the tag and reverting the release commit")
+
+		if pushErr := pushRefSpecs(ctx, c.repo, deleteRefSpec(tagRef)); pushErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to delete remote tag %s: %w", tagName, pushErr))
+		}
+
+		if tagErr := c.repo.DeleteTag(tagName); tagErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to delete tag %s: %w", tagName, tagErr))
+		}
+
+		// otherwise, the next run adds the release to the changelog a second time.
+		if revertErr := c.revertReleaseCommit(ctx, commit); revertErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to revert release commit %s: %w", commit, revertErr))
+		}
+	case RecoveryRecord:
+		c.logger.Warn().Err(err).Str("tag", tagName).Msg("publishing failed, recording the tag as unpublished")
+
+		tag, refErr := c.repo.Reference(tagRef, true)
+		if refErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to get tag %s: %w", tagName, refErr))
+		}
+
+		unpublishedRef := plumbing.NewHashReference(unpublishedRefPrefix+plumbing.ReferenceName(tagName), tag.Hash())
+
+		if refErr = c.repo.Storer.SetReference(unpublishedRef); refErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to record tag %s: %w", tagName, refErr))
+		}
+
+		if pushErr := pushRefSpecs(ctx, c.repo, refSpec(unpublishedRef.Name())); pushErr != nil {
+			return errors.Join(err, fmt.Errorf("failed to push record of tag %s: %w", tagName, pushErr))
+		}
+	}
+
+	return err
+}
+
+// revertReleaseCommit commits the project directory of the parent of the release commit on top of HEAD
+// and pushes the branch. Changes of other directories since the release commit are kept.
+func (c *Project) revertReleaseCommit(ctx context.Context, commit plumbing.Hash) error {
+	head, err := c.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// on a detached HEAD, only the tag was pushed.
+	if !head.Name().IsBranch() {
+		return nil
+	}
+
+	releaseCommit, err := c.repo.CommitObject(commit)
+	if err != nil {
+		return fmt.Errorf("failed to get release commit: %w", err)
+	}
+
+	if releaseCommit.NumParents() == 0 {
+		return ErrRootReleaseCommit
+	}
+
+	parent, err := releaseCommit.Parent(0)
+	if err != nil {
+		return fmt.Errorf("failed to get parent of release commit: %w", err)
+	}
+
+	projectPath := filepath.ToSlash(c.projectPath)
+
+	treeHash, err := c.rebaseTree(parent, head.Hash(), projectPath)
+	if err != nil {
+		return err
+	}
+
+	commitOptions := &git.CommitOptions{}
+
+	// loads the author and committer from the git config.
+	if err = commitOptions.Validate(c.repo); err != nil {
+		return fmt.Errorf("failed to get commit signature: %w", err)
+	}
+
+	obj := c.repo.Storer.NewEncodedObject()
+
+	// the message of git revert, the release detection drops the release commit and its revert.
+	if err = (&object.Commit{
+		Author:       *commitOptions.Author,
+		Committer:    *commitOptions.Committer,
+		Message:      fmt.Sprintf("Revert \"%s\"\n\nThis reverts commit %s.\n", firstLine(releaseCommit.Message), releaseCommit.Hash),
+		TreeHash:     treeHash,
+		ParentHashes: []plumbing.Hash{head.Hash()},
+	}).Encode(obj); err != nil {
+		return fmt.Errorf("failed to encode commit: %w", err)
+	}
+
+	revertCommit, err := c.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return fmt.Errorf("failed to store commit: %w", err)
+	}
+
+	worktree, err := c.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	// moves the branch and restores the changelog, local changes are kept.
+	if err = worktree.Reset(&git.ResetOptions{Commit: revertCommit, Mode: git.MergeReset}); err != nil {
+		return fmt.Errorf("failed to reset worktree: %w", err)
+	}
+
+	return pushRefSpecs(ctx, c.repo, refSpec(head.Name()))
+}
+
+// RecordsUnpublished reports whether the project records tags, which failed to publish. See RecoveryRecord.
+func (c *Project) RecordsUnpublished() bool {
+	return c.config.Recovery.OnPublishFailure == RecoveryRecord
+}
+
+// FetchUnpublished fetches the records of unpublished tags of all projects and returns the recorded tag names.
+// See RecoveryRecord.
+func FetchUnpublished(ctx context.Context, repo *git.Repository) ([]string, error) {
+	err := repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs:   []config.RefSpec{"+" + unpublishedRefPrefix + "*:" + unpublishedRefPrefix + "*"},
+		Prune:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil, fmt.Errorf("failed to fetch unpublished tags: %w", err)
+	}
+
+	refs, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get references: %w", err)
+	}
+
+	var tagNames []string
+
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if tagName, ok := strings.CutPrefix(ref.Name().String(), unpublishedRefPrefix); ok {
+			tagNames = append(tagNames, tagName)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to iterate references: %w", err)
+	}
+
+	return tagNames, nil
+}
+
+// DeleteUnpublished deletes the record of the published tag locally and on the remote.
+func DeleteUnpublished(ctx context.Context, repo *git.Repository, tagName string) error {
+	name := plumbing.ReferenceName(unpublishedRefPrefix + tagName)
+
+	if err := pushRefSpecs(ctx, repo, deleteRefSpec(name)); err != nil {
+		return fmt.Errorf("failed to delete record of tag %s: %w", tagName, err)
+	}
+
+	if err := repo.Storer.RemoveReference(name); err != nil {
+		return fmt.Errorf("failed to delete record of tag %s: %w", tagName, err)
+	}
+
+	return nil
+}
+
+// pushRefSpecs pushes the refspecs to the remote.
+func pushRefSpecs(ctx context.Context, repo *git.Repository, refSpecs ...config.RefSpec) error {
+	err := repo.PushContext(ctx, &git.PushOptions{RemoteName: git.DefaultRemoteName, RefSpecs: refSpecs})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return err //nolint:wrapcheck
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRecoveryProject returns the project test in a clone of the remote on disk.
+func newTestRecoveryProject(t *testing.T, remote string, commands ConfigCommands, recovery ConfigRecovery) *Project {
+	t.Helper()
+
+	repo, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: remote})
+	require.NoError(t, err)
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@example.com"
+	require.NoError(t, repo.SetConfig(cfg))
+
+	project := newTestProject(t)
+	project.repo = repo
+	project.conf = &config.Config{GitPushAttempts: 1}
+	project.config.Commands = commands
+	project.config.Recovery = recovery
+
+	return project
+}
+
+func releaseTestProject(t *testing.T, project *Project) error {
+	t.Helper()
+
+	changes := changelog.New()
+	changes.AddFix("fix: it", "1234567")
+
+	return project.Release(context.Background(), *semver.New(1, 2, 3, "", ""), changes, &sync.Mutex{})
+}
+
+func remoteRef(t *testing.T, remote string, name plumbing.ReferenceName) *plumbing.Reference {
+	t.Helper()
+
+	repo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+
+	ref, err := repo.Reference(name, true)
+	if err != nil {
+		require.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+		return nil
+	}
+
+	return ref
+}
+
+func TestRecoveryPublishBeforePush(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	initial := remoteRef(t, remote, plumbing.Master)
+
+	project := newTestRecoveryProject(t, remote,
+		ConfigCommands{Publish: Hook{Command: "exit 1"}},
+		ConfigRecovery{PublishBeforePush: true},
+	)
+
+	require.ErrorContains(t, releaseTestProject(t, project), "publishNewVersion hook failed")
+
+	assert.Equal(t, initial.Hash(), remoteRef(t, remote, plumbing.Master).Hash())
+	assert.Nil(t, remoteRef(t, remote, plumbing.NewTagReferenceName("test/v1.2.3")))
+
+	head, err := project.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, initial.Hash(), head.Hash())
+
+	_, err = project.repo.Tag("test/v1.2.3")
+	require.ErrorIs(t, err, git.ErrTagNotFound)
+}
+
+func TestRecoveryPublishBeforePushPushFails(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	log := filepath.Join(t.TempDir(), "hooks.log")
+
+	project := newTestRecoveryProject(t, remote,
+		ConfigCommands{Publish: Hook{Command: "echo 'publish {{ .tagName }}' >> " + log}},
+		ConfigRecovery{PublishBeforePush: true},
+	)
+
+	// the remote branch moved, the release commit is not reapplied with a single push attempt.
+	other, otherFS := newTestClone(t, remote)
+	commitFile(t, other, otherFS, "README.md", "# moved\n")
+
+	err := releaseTestProject(t, project)
+	require.ErrorIs(t, err, ErrPublishedNotPushed)
+	require.ErrorContains(t, err, "test/v1.2.3")
+
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "publish test/v1.2.3\n", string(content))
+}
+
+func TestDiscardReleaseRootCommit(t *testing.T) {
+	t.Parallel()
+
+	project := newTestProject(t)
+	createTestTags(t, project, "test/v1.2.3")
+
+	head, err := project.repo.Head()
+	require.NoError(t, err)
+
+	err = project.discardRelease(*semver.New(1, 2, 3, "", ""), head.Hash(), errors.New("publish failed"))
+	require.ErrorIs(t, err, ErrRootReleaseCommit)
+	require.ErrorContains(t, err, "publish failed")
+}
+
+func TestRecoveryDeleteTag(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	initial := remoteRef(t, remote, plumbing.Master)
+
+	project := newTestRecoveryProject(t, remote,
+		ConfigCommands{Publish: Hook{Command: "exit 1"}},
+		ConfigRecovery{OnPublishFailure: RecoveryDeleteTag},
+	)
+
+	require.ErrorContains(t, releaseTestProject(t, project), "publishNewVersion hook failed")
+
+	assert.Nil(t, remoteRef(t, remote, plumbing.NewTagReferenceName("test/v1.2.3")))
+
+	_, err := project.repo.Tag("test/v1.2.3")
+	require.ErrorIs(t, err, git.ErrTagNotFound)
+
+	// the release commit is reverted, so the next run writes the changelog entry once.
+	remoteRepo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+
+	revertCommit, err := remoteRepo.CommitObject(remoteRef(t, remote, plumbing.Master).Hash())
+	require.NoError(t, err)
+	assert.Equal(t, "Revert \"chore(test): release 1.2.3 [skip ci]\"\n\nThis reverts commit "+revertCommit.ParentHashes[0].String()+".\n", revertCommit.Message)
+
+	initialCommit, err := remoteRepo.CommitObject(initial.Hash())
+	require.NoError(t, err)
+	assert.Equal(t, initialCommit.TreeHash, revertCommit.TreeHash)
+
+	head, err := project.repo.Head()
+	require.NoError(t, err)
+	assert.Equal(t, revertCommit.Hash, head.Hash())
+
+	releaseCommit, err := remoteRepo.CommitObject(revertCommit.ParentHashes[0])
+	require.NoError(t, err)
+	assert.Empty(t, project.dropReverts([]*object.Commit{revertCommit, releaseCommit}))
+}
+
+func TestRecoveryRecord(t *testing.T) {
+	t.Parallel()
+
+	remote := newTestRemote(t)
+	recovery := ConfigRecovery{OnPublishFailure: RecoveryRecord}
+
+	project := newTestRecoveryProject(t, remote, ConfigCommands{Publish: Hook{Command: "exit 1"}}, recovery)
+	require.True(t, project.RecordsUnpublished())
+	require.ErrorContains(t, releaseTestProject(t, project), "publishNewVersion hook failed")
+
+	tag := remoteRef(t, remote, plumbing.NewTagReferenceName("test/v1.2.3"))
+	require.NotNil(t, tag)
+
+	record := remoteRef(t, remote, "refs/releaser/unpublished/test/v1.2.3")
+	require.NotNil(t, record)
+	assert.Equal(t, tag.Hash(), record.Hash())
+
+	// the next run fetches the records.
+	project = newTestRecoveryProject(t, remote, ConfigCommands{}, recovery)
+
+	tagNames, err := FetchUnpublished(context.Background(), project.repo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test/v1.2.3"}, tagNames)
+
+	require.NoError(t, DeleteUnpublished(context.Background(), project.repo, "test/v1.2.3"))
+	assert.Nil(t, remoteRef(t, remote, "refs/releaser/unpublished/test/v1.2.3"))
+
+	tagNames, err = FetchUnpublished(context.Background(), project.repo)
+	require.NoError(t, err)
+	assert.Empty(t, tagNames)
+}
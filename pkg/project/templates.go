@@ -0,0 +1,124 @@
+package project
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+)
+
+// templateFuncs are the helper functions available in command templates.
+//
+//nolint:gochecknoglobals
+var templateFuncs = template.FuncMap{
+	// semver parses a version, e.g. {{ (semver "1.2.3").Major }}.
+	"semver": semver.NewVersion,
+	// quote quotes the value for POSIX shells, e.g. {{ .changelog | quote }}.
+	"quote": func(value any) string {
+		return "'" + strings.ReplaceAll(fmt.Sprint(value), "'", `'\''`) + "'"
+	},
+	"env": os.Getenv,
+	"replace": func(old, replacement string, value any) string {
+		return strings.ReplaceAll(fmt.Sprint(value), old, replacement)
+	},
+	"trimPrefix": func(prefix string, value any) string {
+		return strings.TrimPrefix(fmt.Sprint(value), prefix)
+	},
+	"toJson": func(value any) (string, error) {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode json: %w", err)
+		}
+
+		return string(data), nil
+	},
+}
+
+// templateData returns the context of command templates. nextVersion and currentVersion are semver objects,
+// e.g. {{ .nextVersion.Major }}. The version is nil, if the next version is not computed yet.
+// In this case nextVersion, bump and tagName are empty. If the tag pattern contains {sha} or {shortSha},
+// tagName is empty until the release commit exists, i.e. it is only set from publishNewVersion onward.
+func (c *Project) templateData(version *semver.Version, changelogEntries *changelog.Changelog) map[string]any {
+	data := map[string]any{
+		"nextVersion":    "",
+		"currentVersion": c.currentVersion,
+		"bump":           "",
+		"tagName":        "",
+		"commitSha":      "",
+		"branch":         "",
+		"changelog":      "",
+		"remoteURL":      "",
+		"repoRoot":       "",
+		"projectName":    c.name,
+		"projectPath":    c.projectPath,
+	}
+
+	if changelogEntries != nil {
+		data["changelog"] = changelogEntries.String()
+	}
+
+	// without any commits, there is no HEAD.
+	if head, err := c.repo.Head(); err == nil {
+		data["commitSha"] = head.Hash().String()
+
+		if head.Name().IsBranch() {
+			data["branch"] = head.Name().Short()
+		}
+	}
+
+	if remote, err := c.repo.Remote("origin"); err == nil {
+		data["remoteURL"] = remote.Config().URLs[0]
+	}
+
+	if worktree, err := c.repo.Worktree(); err == nil {
+		data["repoRoot"] = worktree.Filesystem.Root()
+	}
+
+	if version != nil {
+		data["nextVersion"] = version
+		data["bump"] = bumpType(c.currentVersion, version)
+
+		// with {sha} or {shortSha}, the tag name is only known once the release commit exists.
+		if !c.tagPattern.HasCommitHash() {
+			data["tagName"] = c.getGitTag(*version, "")
+		}
+	}
+
+	return data
+}
+
+// renderTemplate renders the command template of the stage.
+func renderTemplate(stage stage, cmd string, data map[string]any) (string, error) {
+	tmpl, err := template.New(string(stage)).Funcs(templateFuncs).Parse(cmd)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse %s command template: %w", stage, err)
+	}
+
+	var buf strings.Builder
+
+	if err = tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to execute %s command template: %w", stage, err)
+	}
+
+	return buf.String(), nil
+}
+
+// bumpType returns the part of the version, which changed between both versions: major, minor, patch or prerelease.
+func bumpType(current, next *semver.Version) string {
+	switch {
+	case current.Major() != next.Major():
+		return "major"
+	case current.Minor() != next.Minor():
+		return "minor"
+	case current.Patch() != next.Patch():
+		return "patch"
+	case current.Prerelease() != next.Prerelease():
+		return "prerelease"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,115 @@
+package project
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/jkroepke/semantic-releaser/pkg/changelog"
+	"github.com/jkroepke/semantic-releaser/pkg/tag"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestProject returns a project named test with the current version 1.2.2 in an empty in-memory repository.
+func newTestProject(t *testing.T) *Project {
+	t.Helper()
+
+	repo, err := git.Init(memory.NewStorage(), memfs.New())
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{"https://github.com/jkroepke/test.git"}})
+	require.NoError(t, err)
+
+	tagPattern, err := tag.New("{project}/{version}", "v", "test")
+	require.NoError(t, err)
+
+	return &Project{
+		name:                "test",
+		projectPath:         "charts/test",
+		currentVersion:      semver.New(1, 2, 2, "", ""),
+		tagPattern:          tagPattern,
+		logger:              zerolog.Nop(),
+		config:              Config{Exclude: ConfigExclude{Markers: defaultSkipMarkers}},
+		regexpReleaseCommit: releaseCommitRegexp("test"),
+		repo:                repo,
+	}
+}
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	project := newTestProject(t)
+	version := semver.New(2, 0, 0, "", "")
+
+	changes := changelog.New()
+	changes.AddFix("fix: it's fixed", "1234567")
+
+	data := project.templateData(version, changes)
+
+	for _, tc := range []struct {
+		name     string
+		cmd      string
+		expected string
+	}{
+		{"version", "helm package --version {{ .nextVersion }} {{ .projectPath }}", "helm package --version 2.0.0 charts/test"},
+		{"semver parts", "{{ .nextVersion.Major }}.{{ .currentVersion.Minor }}", "2.2"},
+		{"semver", `{{ (semver "v3.4.5").Patch }}`, "5"},
+		{"context", "{{ .bump }} {{ .tagName }} {{ .remoteURL }} {{ .repoRoot }}", "major test/v2.0.0 https://github.com/jkroepke/test.git /"},
+		{"quote", `echo {{ "it's" | quote }}`, `echo 'it'\''s'`},
+		{"replace", `{{ .projectName | replace "t" "T" }}`, "TesT"},
+		{"trimPrefix", `{{ .tagName | trimPrefix "test/" }}`, "v2.0.0"},
+		{"toJson", `{{ toJson .projectName }}`, `"test"`},
+		{"changelog", `{{ if .changelog }}changelog{{ end }}`, "changelog"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, err := renderTemplate(stagePublish, tc.cmd, data)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cmd)
+		})
+	}
+}
+
+func TestRenderTemplateWithoutVersion(t *testing.T) {
+	t.Parallel()
+
+	cmd, err := renderTemplate(stageVerifyConditions, "[{{ .nextVersion }}][{{ .bump }}] {{ .currentVersion }}", newTestProject(t).templateData(nil, nil))
+	require.NoError(t, err)
+	assert.Equal(t, "[][] 1.2.2", cmd)
+}
+
+func TestTemplateDataTagNameWithCommitHash(t *testing.T) {
+	t.Parallel()
+
+	project := newTestProject(t)
+
+	tagPattern, err := tag.New("{project}/{version}+{shortSha}", "", "test")
+	require.NoError(t, err)
+
+	project.tagPattern = tagPattern
+
+	// the hash of the release commit is not known before the commit.
+	assert.Equal(t, "", project.templateData(semver.New(2, 0, 0, "", ""), nil)["tagName"])
+}
+
+func TestBumpType(t *testing.T) {
+	t.Parallel()
+
+	current := semver.MustParse("1.2.3")
+
+	for next, expected := range map[string]string{
+		"2.0.0":      "major",
+		"1.3.0":      "minor",
+		"1.2.4":      "patch",
+		"1.2.3-rc.1": "prerelease",
+		"1.2.3":      "",
+	} {
+		assert.Equal(t, expected, bumpType(current, semver.MustParse(next)), next)
+	}
+}
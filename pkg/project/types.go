@@ -1,18 +1,34 @@
 package project
 
 import (
+	"fmt"
+	"regexp"
+	"time"
+
 	"github.com/Masterminds/semver/v3"
 	"github.com/go-git/go-git/v5"
 	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/mask"
+	"github.com/jkroepke/semantic-releaser/pkg/tag"
 	cc "github.com/leodido/go-conventionalcommits"
 	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
 )
 
 type Project struct {
 	name           string
 	projectPath    string
 	currentVersion *semver.Version
-	config         Config
+	currentTag     string
+	initialVersion *semver.Version
+	tagPattern     tag.Pattern
+	// legacyTagPatterns are only used to read the current version.
+	legacyTagPatterns []tag.Pattern
+	// regexpReleaseCommit matches the subject of release commits of the project.
+	regexpReleaseCommit *regexp.Regexp
+	config              Config
+	// masker redacts secrets from the output of hook commands.
+	masker *mask.Masker
 
 	logger       zerolog.Logger
 	conf         *config.Config
@@ -21,10 +37,212 @@ type Project struct {
 }
 
 type Config struct {
-	Commands ConfigCommands `yaml:"commands"`
+	// InitialDevelopment enables the semver 0.x semantics. While the major version is 0,
+	// breaking changes bump the minor version and features bump the patch version.
+	// Use a Release-As: 1.0.0 footer to graduate to 1.0.0.
+	InitialDevelopment bool `yaml:"initialDevelopment"`
+	// InitialVersion is the version of the first release, if no tag of the project exists.
+	// It replaces the version computed from the commits, e.g. a feat commit still releases the initial version.
+	// Only a Release-As footer or --release-as takes precedence. Without releasable commits, nothing is released.
+	InitialVersion string `yaml:"initialVersion"`
+	// InitialVersionFile is a YAML file relative to the project directory, e.g. Chart.yaml.
+	// The top-level version key is used like InitialVersion and takes precedence over InitialVersion.
+	InitialVersionFile string `yaml:"initialVersionFile"`
+	// Exclude defines commits, which are ignored by the release detection.
+	Exclude   ConfigExclude   `yaml:"exclude"`
+	Changelog ConfigChangelog `yaml:"changelog"`
+	Commands  ConfigCommands  `yaml:"commands"`
+	Recovery  ConfigRecovery  `yaml:"recovery"`
+}
+
+// ConfigRecovery defines the recovery of a failed publishNewVersion command.
+type ConfigRecovery struct {
+	// PublishBeforePush runs publishNewVersion before the release commit and tag are pushed.
+	// If it fails, the local release commit and tag are discarded. If the push fails afterward, the release fails
+	// loudly, since the version is public without tag. Tag patterns with {sha} or {shortSha} are not supported,
+	// since the release commit may be reapplied onto the moved remote branch before the push.
+	PublishBeforePush bool `yaml:"publishBeforePush"`
+	// OnPublishFailure is the recovery, if publishNewVersion fails after the push. See RecoveryNone,
+	// RecoveryDeleteTag and RecoveryRecord. Not supported with PublishBeforePush.
+	OnPublishFailure string `yaml:"onPublishFailure"`
+}
+
+const (
+	// RecoveryNone keeps the pushed tag. The version is not released again.
+	RecoveryNone = "none"
+	// RecoveryDeleteTag deletes the local and remote tag and reverts the release commit,
+	// so the next run releases the version again.
+	RecoveryDeleteTag = "deleteTag"
+	// RecoveryRecord records the tag as unpublished. The next run checks out the tag and retries publishNewVersion,
+	// before any project is released.
+	RecoveryRecord = "record"
+)
+
+type ConfigChangelog struct {
+	// Format is the format of the changelog file: markdown, keepachangelog, asciidoc or json. Defaults to markdown.
+	Format string `yaml:"format"`
+	// Authors renders the author and co-authors on each entry.
+	Authors bool `yaml:"authors"`
+	// Contributors renders a section with all authors and co-authors of the release.
+	Contributors bool `yaml:"contributors"`
+	// UsernamesFile is a YAML file relative to the repository root, mapping email addresses to usernames.
+	UsernamesFile string `yaml:"usernamesFile"`
+	// Issues link references to issue trackers in messages and footers.
+	Issues []ConfigIssuePattern `yaml:"issues"`
+	// IssuesList renders a section with all issue references of the release.
+	IssuesList bool `yaml:"issuesList"`
+	// GroupByScope groups the entries by scope inside each section.
+	GroupByScope bool `yaml:"groupByScope"`
+	// Sort sorts the entries by scope and description inside each section.
+	Sort bool `yaml:"sort"`
+	// DateFormat is the layout of the release date, see time.Layout. Defaults to 2006-01-02.
+	DateFormat string `yaml:"dateFormat"`
+}
+
+type ConfigIssuePattern struct {
+	// Pattern matches the reference, e.g. \b[A-Z]+-\d+\b.
+	Pattern *Regexp `yaml:"pattern"`
+	// URL is the link target. Submatches of the pattern can be used, e.g. https://jira.example.com/browse/$0.
+	URL string `yaml:"url"`
+}
+
+type ConfigExclude struct {
+	// Markers exclude commits containing one of the markers in the commit message.
+	Markers []string `yaml:"markers"`
+	// Rules exclude commits matching all defined fields of a rule.
+	Rules []ConfigExcludeRule `yaml:"rules"`
 }
 
+type ConfigExcludeRule struct {
+	// Author matches the author of the commit in the format "Name <email>".
+	Author *Regexp `yaml:"author"`
+	// Scope matches the scope of the conventional commit.
+	Scope *Regexp `yaml:"scope"`
+	// Subject matches the first line of the commit message.
+	Subject *Regexp `yaml:"subject"`
+}
+
+// ConfigCommands are the hook commands of the release lifecycle, in order of execution.
+// Each command is a template, e.g. {{ .nextVersion }}. The release data is available as SEMREL_* environment
+// variables as well, the release notes as file SEMREL_RELEASE_NOTES_FILE.
 type ConfigCommands struct {
-	SetNewVersion string `yaml:"setNewVersion"`
-	Publish       string `yaml:"publishNewVersion"`
+	// Shell runs string commands as argv list, e.g. [bash, -euo, pipefail, -c]. The command is appended as last argument.
+	// Defaults to [sh, -c], or [cmd, /C] on windows.
+	Shell []string `yaml:"shell"`
+	// VerifyConditions runs before the release detection, e.g. to check credentials.
+	VerifyConditions Hook `yaml:"verifyConditions"`
+	// VerifyRelease runs after the next version is computed.
+	VerifyRelease Hook `yaml:"verifyRelease"`
+	SetNewVersion Hook `yaml:"setNewVersion"`
+	// Prepare runs before the release commit, e.g. to build or package the project.
+	Prepare Hook `yaml:"prepare"`
+	Publish Hook `yaml:"publishNewVersion"`
+	// Success runs after a successful release.
+	Success Hook `yaml:"success"`
+	// Fail runs if any other command or the release commit failed.
+	Fail Hook `yaml:"fail"`
+}
+
+// Hook is a hook command. It can be defined as shell string, as argv list or as map.
+// Each argument of an argv list is rendered separately and executed without a shell,
+// e.g. ["helm", "package", "{{ .projectPath }}"].
+type Hook struct {
+	// Command is executed by the shell.
+	Command string `yaml:"command"`
+	// Args is executed without a shell. Command and Args are mutually exclusive.
+	Args []string `yaml:"args"`
+	// Shell overrides the shell of ConfigCommands. Shell and Args are mutually exclusive.
+	Shell []string `yaml:"shell"`
+	// Timeout cancels the command after the duration, e.g. 10m. Zero means no timeout.
+	Timeout time.Duration `yaml:"timeout"`
+	// Retry retries the command on failure.
+	Retry Retry `yaml:"retry"`
+}
+
+// Retry is the retry policy of a hook command. Without exitCodes and output, all failures are retried.
+type Retry struct {
+	// Attempts is the maximum number of attempts, at least 1. Defaults to 1.
+	Attempts int `yaml:"attempts"`
+	// Backoff is the delay before the second attempt. The delay doubles after each attempt, up to maxRetryBackoff.
+	Backoff time.Duration `yaml:"backoff"`
+	// ExitCodes are the exit codes, which are retried.
+	ExitCodes []int `yaml:"exitCodes"`
+	// Output matches the stdout or stderr of failures, which are retried.
+	Output *Regexp `yaml:"output"`
+}
+
+func (h *Hook) UnmarshalYAML(value *yaml.Node) error {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return value.Decode(&h.Command) //nolint:wrapcheck
+	case yaml.SequenceNode:
+		return value.Decode(&h.Args) //nolint:wrapcheck
+	}
+
+	// the type alias prevents the recursion into UnmarshalYAML.
+	type hook Hook
+
+	if err := value.Decode((*hook)(h)); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if h.Command != "" && len(h.Args) != 0 {
+		return fmt.Errorf("line %d: %w", value.Line, ErrHookCommandAndArgs)
+	}
+
+	// args are executed without a shell.
+	if len(h.Shell) != 0 && len(h.Args) != 0 {
+		return fmt.Errorf("line %d: %w", value.Line, ErrHookShellAndArgs)
+	}
+
+	return nil
+}
+
+func (r *Retry) UnmarshalYAML(value *yaml.Node) error {
+	// the type alias prevents the recursion into UnmarshalYAML.
+	type retry Retry
+
+	retryValue := retry{Attempts: 1}
+
+	if err := value.Decode(&retryValue); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	if retryValue.Attempts < 1 {
+		return fmt.Errorf("line %d: %d: %w", value.Line, retryValue.Attempts, ErrInvalidRetryAttempts)
+	}
+
+	if retryValue.Backoff < 0 {
+		return fmt.Errorf("line %d: %s: %w", value.Line, retryValue.Backoff, ErrInvalidRetryBackoff)
+	}
+
+	*r = Retry(retryValue)
+
+	return nil
+}
+
+// empty reports whether the hook has no command.
+func (h Hook) empty() bool {
+	return h.Command == "" && len(h.Args) == 0
+}
+
+// Regexp is a regular expression, which can be decoded from YAML.
+type Regexp struct {
+	*regexp.Regexp
+}
+
+func (r *Regexp) UnmarshalYAML(value *yaml.Node) error {
+	var expr string
+	if err := value.Decode(&expr); err != nil {
+		return err //nolint:wrapcheck
+	}
+
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+
+	r.Regexp = compiled
+
+	return nil
 }
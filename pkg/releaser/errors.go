@@ -0,0 +1,8 @@
+package releaser
+
+import "errors"
+
+var (
+	ErrNoProjectForTag         = errors.New("no project found for tag")
+	ErrReleaseAsUnknownProject = errors.New("release-as refers to an unknown project")
+)
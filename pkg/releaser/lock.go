@@ -0,0 +1,74 @@
+package releaser
+
+import "sync"
+
+// gitLock serializes the changes of the repository, e.g. the release commits and pushes, of projects released in
+// parallel. The first lock of each project is granted in project order, once all preceding projects have passed
+// their turn. Afterwards, the project locks like a plain mutex.
+type gitLock struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+	// next is the index of the project whose turn it is.
+	next int
+	// passed reports per project whether the project has passed its turn.
+	passed []bool
+	held   bool
+}
+
+func newGitLock(projects int) *gitLock {
+	lock := &gitLock{passed: make([]bool, projects)}
+	lock.cond = sync.NewCond(&lock.mu)
+
+	return lock
+}
+
+// turn returns the lock of the project at index i.
+func (l *gitLock) turn(i int) *turn {
+	return &turn{lock: l, index: i}
+}
+
+// pass marks the turn of the project at index i as passed. The lock must be held.
+func (l *gitLock) pass(i int) {
+	l.passed[i] = true
+
+	for l.next < len(l.passed) && l.passed[l.next] {
+		l.next++
+	}
+
+	l.cond.Broadcast()
+}
+
+// turn is the lock of a single project. It implements sync.Locker.
+type turn struct {
+	lock  *gitLock
+	index int
+}
+
+// Lock waits for the turn of the project and for the release of the lock by any other project.
+func (t *turn) Lock() {
+	t.lock.mu.Lock()
+	defer t.lock.mu.Unlock()
+
+	for t.lock.held || (!t.lock.passed[t.index] && t.lock.next != t.index) {
+		t.lock.cond.Wait()
+	}
+
+	t.lock.held = true
+}
+
+// Unlock releases the lock and passes the turn of the project.
+func (t *turn) Unlock() {
+	t.lock.mu.Lock()
+	defer t.lock.mu.Unlock()
+
+	t.lock.held = false
+	t.lock.pass(t.index)
+}
+
+// Done passes the turn of the project, if the project did not lock, e.g. because it was skipped or failed.
+func (t *turn) Done() {
+	t.lock.mu.Lock()
+	defer t.lock.mu.Unlock()
+
+	t.lock.pass(t.index)
+}
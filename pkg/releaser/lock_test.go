@@ -0,0 +1,51 @@
+package releaser
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGitLock(t *testing.T) {
+	t.Parallel()
+
+	lock := newGitLock(4)
+
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+
+	// the projects lock in reverse order, project 1 is skipped.
+	for _, i := range []int{3, 2, 0} {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			turn := lock.turn(i)
+			defer turn.Done()
+
+			turn.Lock()
+
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+
+			turn.Unlock()
+
+			// after the turn, the lock works like a mutex.
+			turn.Lock()
+			turn.Unlock()
+		}()
+	}
+
+	lock.turn(1).Done()
+
+	wg.Wait()
+
+	assert.Equal(t, []int{0, 2, 3}, order)
+	assert.Equal(t, 4, lock.next)
+}
@@ -1,17 +1,38 @@
 package releaser
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
 	"sync"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/history"
 	"github.com/jkroepke/semantic-releaser/pkg/project"
 	cc "github.com/leodido/go-conventionalcommits"
 	"github.com/rs/zerolog"
 )
 
+// result is the result of the release of a single project.
+type result struct {
+	project string
+	status  string
+	version string
+	err     error
+}
+
+const (
+	statusReleased = "released"
+	statusSkipped  = "skipped"
+	statusFailed   = "failed"
+)
+
 // Releaser handles the release process for Helm charts.
 type Releaser struct {
 	logger       zerolog.Logger
@@ -26,69 +47,296 @@ func New(logger zerolog.Logger, conf *config.Config, repo *git.Repository, commi
 }
 
 // Run executes the release process for all Helm charts found in the configured directory.
+// Tags recorded as unpublished are published first. The git history is walked once for all projects. Canceling the context stops all running hook commands.
+// Up to Concurrency projects are released in parallel, in order of their names. The hooks run in parallel, but the
+// release commits and pushes are serialized in order of the project names. A project, which fails to load, is
+// skipped. The errors of all projects are joined, and a summary of all projects is logged.
 //
 //nolint:cyclop
-func (r *Releaser) Run() error {
+func (r *Releaser) Run(ctx context.Context) error {
 	wg := sync.WaitGroup{}
 
-	worktree, err := r.repo.Worktree()
+	loaded, failed, err := r.loadProjects()
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+		return err
 	}
 
-	projects, err := worktree.Filesystem.ReadDir(r.conf.ProjectsDir)
-	if err != nil {
-		return fmt.Errorf("failed to read project directories: %w", err)
+	if err = r.checkReleaseAs(loaded, failed); err != nil {
+		return err
 	}
 
-	errCh := make(chan error, len(projects))
+	// the retries check out the recorded tags, before any project is released.
+	errs := r.retryUnpublished(ctx, loaded)
+
+	projects := make([]*project.Project, 0, len(loaded))
+	historyRanges := make([]history.Range, 0, len(loaded))
+
+	for _, proj := range loaded {
+		historyRange, rangeErr := proj.HistoryRange()
+		if rangeErr != nil {
+			failed = append(failed, result{project: proj.Name(), status: statusFailed, err: fmt.Errorf("failed to get history range: %w", rangeErr)})
 
-	for _, projectDir := range projects {
-		if !projectDir.IsDir() {
 			continue
 		}
 
+		projects = append(projects, proj)
+		historyRanges = append(historyRanges, historyRange)
+	}
+
+	commits, err := history.Collect(r.repo, historyRanges, history.Options{
+		FirstParent: r.conf.MergeCommits == config.MergeCommitsFirstParent,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to collect commits: %w", err)
+	}
+
+	results := make([]result, len(projects))
+	semaphore := make(chan struct{}, r.conf.Concurrency)
+	lock := newGitLock(len(projects))
+
+	// the projects are started in order of their names.
+	for i, proj := range projects {
+		semaphore <- struct{}{}
+
 		wg.Add(1)
 
 		go func() {
 			defer wg.Done()
+			defer func() { <-semaphore }()
 
-			proj, err := project.New(r.logger, r.conf, r.repo, r.commitParser, projectDir.Name())
-			if err != nil {
-				if errors.Is(err, project.ErrProjectFileNotFound) {
-					return
-				}
+			// the following projects wait for the turn, even if the project is not released.
+			gitLock := lock.turn(i)
+			defer gitLock.Done()
 
-				errCh <- fmt.Errorf("failed to initialize project: %w", err)
-			}
+			results[i] = r.releaseProject(ctx, proj, commits[i], gitLock)
+			results[i].project = proj.Name()
+		}()
+	}
 
-			nextVersion, changelog, err := proj.DetectRelease()
-			if err != nil {
-				errCh <- err
+	wg.Wait()
 
-				return
-			}
+	results = append(results, failed...)
+	slices.SortFunc(results, func(a, b result) int { return strings.Compare(a.project, b.project) })
 
-			if changelog.Len() == 0 {
-				return
-			}
+	for _, res := range results {
+		if res.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", res.project, res.err))
+		}
+	}
 
-			if err := proj.Release(nextVersion, changelog); err != nil {
-				errCh <- fmt.Errorf("failed to release project: %w", err)
+	r.logSummary(results)
 
-				return
-			}
-		}()
+	return errors.Join(errs...)
+}
+
+// checkReleaseAs returns an error, if --release-as refers to a project, which does not exist.
+// Projects, which failed to load, exist.
+func (r *Releaser) checkReleaseAs(projects []*project.Project, failed []result) error {
+	var errs []error
+
+	for name := range r.conf.ReleaseAs {
+		if !slices.ContainsFunc(projects, func(proj *project.Project) bool { return proj.Name() == name }) &&
+			!slices.ContainsFunc(failed, func(res result) bool { return res.project == name }) {
+			errs = append(errs, fmt.Errorf("%s: %w", name, ErrReleaseAsUnknownProject))
+		}
 	}
 
-	wg.Wait()
-	close(errCh)
+	return errors.Join(errs...)
+}
+
+// retryUnpublished publishes the tags, which are recorded as unpublished, see project.RecoveryRecord.
+// The records of all projects are fetched once. A failed retry keeps the record for the next run,
+// the errors are returned per tag.
+func (r *Releaser) retryUnpublished(ctx context.Context, projects []*project.Project) []error {
+	if !slices.ContainsFunc(projects, (*project.Project).RecordsUnpublished) {
+		return nil
+	}
+
+	tagNames, err := project.FetchUnpublished(ctx, r.repo)
+	if err != nil {
+		return []error{err}
+	}
+
+	var errs []error
+
+	for _, tagName := range tagNames {
+		r.logger.Info().Str("tag", tagName).Msg("retrying to publish")
+
+		if err = r.Publish(ctx, tagName); err != nil {
+			errs = append(errs, fmt.Errorf("%s: failed to retry publishing: %w", tagName, err))
+
+			continue
+		}
+
+		if err = project.DeleteUnpublished(ctx, r.repo, tagName); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", tagName, err))
+		}
+	}
+
+	return errs
+}
+
+// releaseProject runs the release of a single project.
+func (r *Releaser) releaseProject(ctx context.Context, proj *project.Project, commits []*object.Commit, gitLock sync.Locker) result {
+	// the remaining projects are not started after a cancellation.
+	if err := ctx.Err(); err != nil {
+		return result{status: statusFailed, err: err}
+	}
+
+	if err := proj.VerifyConditions(ctx); err != nil {
+		return result{status: statusFailed, err: fmt.Errorf("failed to verify conditions: %w", err)}
+	}
+
+	nextVersion, changelog, err := proj.DetectRelease(commits)
+	if err != nil {
+		return result{status: statusFailed, err: err}
+	}
+
+	if nextVersion.String() == proj.CurrentVersion() {
+		return result{status: statusSkipped, version: proj.CurrentVersion()}
+	}
+
+	if err := proj.Release(ctx, nextVersion, changelog, gitLock); err != nil {
+		return result{status: statusFailed, version: nextVersion.String(), err: fmt.Errorf("failed to release project: %w", err)}
+	}
+
+	return result{status: statusReleased, version: nextVersion.String()}
+}
+
+// logSummary logs the result of each project and the totals.
+func (r *Releaser) logSummary(results []result) {
+	totals := map[string]int{}
+
+	for _, res := range results {
+		totals[res.status]++
+
+		event := r.logger.Info()
+		if res.err != nil {
+			event = r.logger.Error().Err(res.err)
+		}
+
+		event.Str("project", res.project).Str("status", res.status).Str("version", res.version).Msg("summary")
+	}
+
+	r.logger.Info().
+		Int(statusReleased, totals[statusReleased]).
+		Int(statusSkipped, totals[statusSkipped]).
+		Int(statusFailed, totals[statusFailed]).
+		Msg("release summary")
+}
+
+// Publish runs the publishNewVersion hook of the project owning the existing tag, e.g. after a registry outage.
+// The tag is checked out while publishing, afterwards the previous HEAD is restored.
+// Local changes of tracked files fail the checkout.
+func (r *Releaser) Publish(ctx context.Context, tagName string) (err error) {
+	commit, err := r.repo.ResolveRevision(plumbing.Revision(plumbing.NewTagReferenceName(tagName)))
+	if err != nil {
+		return fmt.Errorf("failed to resolve tag %s: %w", tagName, err)
+	}
+
+	head, err := r.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	// HEAD itself, i.e. a symbolic reference to the branch or the hash of a detached HEAD.
+	rawHead, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	if err = worktree.Checkout(&git.CheckoutOptions{Hash: *commit}); err != nil {
+		// the checkout moves HEAD, before it fails on local changes.
+		if restoreErr := r.repo.Storer.SetReference(rawHead); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to restore HEAD: %w", restoreErr))
+		}
+
+		return fmt.Errorf("failed to checkout tag %s: %w", tagName, err)
+	}
+
+	defer func() {
+		restore := &git.CheckoutOptions{Hash: head.Hash()}
+		if head.Name().IsBranch() {
+			restore = &git.CheckoutOptions{Branch: head.Name()}
+		}
+
+		if restoreErr := worktree.Checkout(restore); restoreErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to restore HEAD: %w", restoreErr))
+		}
+	}()
+
+	// the projects are loaded from the state of the tag.
+	projects, failed, err := r.loadProjects()
+	if err != nil {
+		return err
+	}
+
+	for _, proj := range projects {
+		err = proj.Publish(ctx, tagName)
+		if errors.Is(err, project.ErrTagNotOfProject) {
+			continue
+		}
 
-	for err = range errCh {
 		if err != nil {
-			return err //nolint:wrapcheck
+			return fmt.Errorf("failed to publish tag %s: %w", tagName, err)
 		}
+
+		return nil
+	}
+
+	// the tag may belong to a project, which failed to load.
+	errs := []error{fmt.Errorf("%s: %w", tagName, ErrNoProjectForTag)}
+
+	for _, res := range failed {
+		errs = append(errs, fmt.Errorf("%s: %w", res.project, res.err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// loadProjects initializes all projects found in the configured directory.
+// Directories without project config file are skipped. Projects, which fail to initialize, are returned as
+// failed results.
+func (r *Releaser) loadProjects() ([]*project.Project, []result, error) {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	projectDirs, err := worktree.Filesystem.ReadDir(r.conf.ProjectsDir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read project directories: %w", err)
+	}
+
+	slices.SortFunc(projectDirs, func(a, b fs.FileInfo) int { return strings.Compare(a.Name(), b.Name()) })
+
+	projects := make([]*project.Project, 0, len(projectDirs))
+
+	var failed []result
+
+	for _, projectDir := range projectDirs {
+		if !projectDir.IsDir() {
+			continue
+		}
+
+		proj, err := project.New(r.logger, r.conf, r.repo, r.commitParser, projectDir.Name())
+		if err != nil {
+			if errors.Is(err, project.ErrProjectFileNotFound) {
+				continue
+			}
+
+			failed = append(failed, result{project: projectDir.Name(), status: statusFailed, err: fmt.Errorf("failed to initialize project: %w", err)})
+
+			continue
+		}
+
+		projects = append(projects, proj)
 	}
 
-	return nil
+	return projects, failed, nil
 }
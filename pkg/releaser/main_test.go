@@ -0,0 +1,406 @@
+package releaser_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/jkroepke/semantic-releaser/pkg/config"
+	"github.com/jkroepke/semantic-releaser/pkg/releaser"
+	cc "github.com/leodido/go-conventionalcommits"
+	"github.com/leodido/go-conventionalcommits/parser"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func commitFiles(t *testing.T, repo *git.Repository, message string, files map[string]string) {
+	t.Helper()
+
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	for path, content := range files {
+		require.NoError(t, util.WriteFile(worktree.Filesystem, path, []byte(content), 0o644))
+
+		_, err = worktree.Add(path)
+		require.NoError(t, err)
+	}
+
+	_, err = worktree.Commit(message, &git.CommitOptions{})
+	require.NoError(t, err)
+}
+
+// newTestRepository returns a repository on disk with a committer.
+func newTestRepository(t *testing.T) *git.Repository {
+	t.Helper()
+
+	repo, err := git.PlainInit(t.TempDir(), false)
+	require.NoError(t, err)
+
+	setTestUser(t, repo)
+
+	return repo
+}
+
+func setTestUser(t *testing.T, repo *git.Repository) {
+	t.Helper()
+
+	cfg, err := repo.Config()
+	require.NoError(t, err)
+
+	cfg.User.Name = "test"
+	cfg.User.Email = "test@example.com"
+	require.NoError(t, repo.SetConfig(cfg))
+}
+
+func newTestReleaser(repo *git.Repository, conf *config.Config, logs io.Writer) *releaser.Releaser {
+	commitParser := parser.NewMachine(parser.WithTypes(cc.TypesConventional))
+	commitParser.WithBestEffort()
+
+	// the projects log in parallel.
+	return releaser.New(zerolog.New(zerolog.SyncWriter(logs)), conf, repo, commitParser)
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepository(t)
+
+	commitFiles(t, repo, "chore: init", map[string]string{
+		"charts/a/.releaser.yaml": "commands:\n  verifyConditions: exit 1\n",
+		"charts/b/.releaser.yaml": "changelog:\n  sort: true\n",
+		"charts/c/.releaser.yaml": "changelog:\n  sort: true\n",
+	})
+	commitFiles(t, repo, "fix: c", map[string]string{"charts/c/values.yaml": "fixed: true\n"})
+
+	conf := config.New()
+	conf.Concurrency = 2
+
+	var logs bytes.Buffer
+
+	err := newTestReleaser(repo, conf, &logs).Run(context.Background())
+
+	// the release of c fails, since the repository has no remote.
+	require.ErrorContains(t, err, "a: failed to verify conditions")
+	require.ErrorContains(t, err, "c: failed to release project")
+	assert.NotContains(t, err.Error(), "b:")
+
+	assert.Contains(t, logs.String(), `"project":"a","status":"failed"`)
+	assert.Contains(t, logs.String(), `"project":"b","status":"skipped","version":"0.0.0"`)
+	assert.Contains(t, logs.String(), `"project":"c","status":"failed","version":"0.0.1"`)
+	assert.Contains(t, logs.String(), `"released":0,"skipped":1,"failed":2,"message":"release summary"`)
+}
+
+func TestRunRetriesUnpublished(t *testing.T) {
+	t.Parallel()
+
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	log := filepath.Join(t.TempDir(), "hooks.log")
+
+	repo := newTestRepository(t)
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{remote}})
+	require.NoError(t, err)
+
+	commitFiles(t, repo, "chore: init", map[string]string{
+		"charts/test/.releaser.yaml": "recovery:\n  onPublishFailure: record\n" +
+			"commands:\n  publishNewVersion: echo \"publish {{ .tagName }} {{ .nextVersion }} $(cat values.yaml)\" >> " + log + "\n",
+		"charts/test/values.yaml": "tagged",
+	})
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("test/1.0.0", head.Hash(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/releaser/unpublished/test/1.0.0", head.Hash())))
+
+	commitFiles(t, repo, "chore: untagged", map[string]string{"charts/test/values.yaml": "untagged"})
+
+	require.NoError(t, repo.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{
+		"refs/heads/*:refs/heads/*", "refs/tags/*:refs/tags/*", "refs/releaser/*:refs/releaser/*",
+	}}))
+
+	clone, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: remote})
+	require.NoError(t, err)
+
+	setTestUser(t, clone)
+
+	var logs bytes.Buffer
+
+	require.NoError(t, newTestReleaser(clone, config.New(), &logs).Run(context.Background()))
+
+	// the publish command runs on the state of the tag.
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "publish test/1.0.0 1.0.0 tagged\n", string(content))
+
+	remoteRepo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+
+	_, err = remoteRepo.Reference("refs/releaser/unpublished/test/1.0.0", true)
+	require.ErrorIs(t, err, plumbing.ErrReferenceNotFound)
+
+	assert.Contains(t, logs.String(), `"project":"test","status":"skipped","version":"1.0.0"`)
+}
+
+func TestRunConcurrency(t *testing.T) {
+	t.Parallel()
+
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	log := filepath.Join(t.TempDir(), "hooks.log")
+
+	repo := newTestRepository(t)
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{remote}})
+	require.NoError(t, err)
+
+	files := map[string]string{}
+
+	for name, sleep := range map[string]string{"a": "0.5", "b": "0", "c": "0.2", "d": "0"} {
+		files["charts/"+name+"/.releaser.yaml"] = "commands:\n  prepare: echo start {{ .projectName }} >> " + log +
+			"; sleep " + sleep + "; echo end {{ .projectName }} >> " + log + "\n"
+		files["charts/"+name+"/values.yaml"] = "fixed: false\n"
+	}
+
+	commitFiles(t, repo, "chore: init", files)
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		commitFiles(t, repo, "fix: "+name, map[string]string{"charts/" + name + "/values.yaml": "fixed: true\n"})
+	}
+
+	require.NoError(t, repo.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/heads/*:refs/heads/*"}}))
+
+	clone, err := git.PlainClone(t.TempDir(), false, &git.CloneOptions{URL: remote})
+	require.NoError(t, err)
+
+	setTestUser(t, clone)
+
+	conf := config.New()
+	conf.Concurrency = 2
+
+	var logs bytes.Buffer
+
+	require.NoError(t, newTestReleaser(clone, conf, &logs).Run(context.Background()))
+
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+
+	// the prepare hooks run in parallel, up to the concurrency.
+	running, maxRunning := 0, 0
+
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if strings.HasPrefix(line, "start ") {
+			running++
+		} else {
+			running--
+		}
+
+		maxRunning = max(maxRunning, running)
+	}
+
+	assert.Equal(t, 2, maxRunning, string(content))
+
+	// the release commits are pushed in order of the project names, although b and d finished their hooks earlier.
+	remoteRepo, err := git.PlainOpen(remote)
+	require.NoError(t, err)
+
+	head, err := remoteRepo.Head()
+	require.NoError(t, err)
+
+	commits, err := remoteRepo.Log(&git.LogOptions{From: head.Hash()})
+	require.NoError(t, err)
+
+	var releases []string
+
+	require.NoError(t, commits.ForEach(func(commit *object.Commit) error {
+		if subject, _, _ := strings.Cut(commit.Message, "\n"); strings.Contains(subject, "release") {
+			releases = append(releases, subject)
+		}
+
+		return nil
+	}))
+
+	assert.Equal(t, []string{
+		"chore(d): release 0.0.1 [skip ci]",
+		"chore(c): release 0.0.1 [skip ci]",
+		"chore(b): release 0.0.1 [skip ci]",
+		"chore(a): release 0.0.1 [skip ci]",
+	}, releases)
+
+	assert.Contains(t, logs.String(), `"released":4,"skipped":0,"failed":0,"message":"release summary"`)
+}
+
+// newTestPublishRepository returns a repository with the tag test/1.0.0 on the parent of HEAD
+// and the tag other/1.0.0 of a directory without project on HEAD.
+func newTestPublishRepository(t *testing.T, log string) *git.Repository {
+	t.Helper()
+
+	repo := newTestRepository(t)
+
+	commitFiles(t, repo, "chore: init", map[string]string{
+		"charts/test/.releaser.yaml": "commands:\n  publishNewVersion: echo \"{{ .tagName }} $(cat values.yaml)\" >> " + log + "\n",
+		"charts/test/values.yaml":    "tagged",
+	})
+
+	head, err := repo.Head()
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("test/1.0.0", head.Hash(), nil)
+	require.NoError(t, err)
+
+	commitFiles(t, repo, "chore: untagged", map[string]string{"charts/test/values.yaml": "untagged"})
+
+	head, err = repo.Head()
+	require.NoError(t, err)
+
+	_, err = repo.CreateTag("other/1.0.0", head.Hash(), nil)
+	require.NoError(t, err)
+
+	return repo
+}
+
+func TestPublish(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name     string
+		detached bool
+		dirty    bool
+		tagName  string
+		err      error
+		expected string
+	}{
+		{name: "branch", tagName: "test/1.0.0", expected: "test/1.0.0 tagged\n"},
+		{name: "detached HEAD", detached: true, tagName: "test/1.0.0", expected: "test/1.0.0 tagged\n"},
+		{name: "dirty worktree", dirty: true, tagName: "test/1.0.0", err: git.ErrUnstagedChanges},
+		{name: "no project", tagName: "other/1.0.0", err: releaser.ErrNoProjectForTag},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			log := filepath.Join(t.TempDir(), "hooks.log")
+			repo := newTestPublishRepository(t, log)
+
+			worktree, err := repo.Worktree()
+			require.NoError(t, err)
+
+			head, err := repo.Head()
+			require.NoError(t, err)
+
+			if tc.detached {
+				require.NoError(t, worktree.Checkout(&git.CheckoutOptions{Hash: head.Hash()}))
+
+				head, err = repo.Head()
+				require.NoError(t, err)
+			}
+
+			values := "untagged"
+
+			if tc.dirty {
+				values = "dirty"
+				require.NoError(t, util.WriteFile(worktree.Filesystem, "charts/test/values.yaml", []byte(values), 0o644))
+			}
+
+			err = newTestReleaser(repo, config.New(), io.Discard).Publish(context.Background(), tc.tagName)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// the hook runs on the state of the tag.
+			content, err := os.ReadFile(log)
+			if tc.expected == "" {
+				require.ErrorIs(t, err, os.ErrNotExist)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tc.expected, string(content))
+			}
+
+			// HEAD and the worktree are restored.
+			restored, err := repo.Head()
+			require.NoError(t, err)
+			assert.Equal(t, head.Name(), restored.Name())
+			assert.Equal(t, head.Hash(), restored.Hash())
+
+			content, err = util.ReadFile(worktree.Filesystem, "charts/test/values.yaml")
+			require.NoError(t, err)
+			assert.Equal(t, values, string(content))
+		})
+	}
+}
+
+func TestRunReleaseAsUnknownProject(t *testing.T) {
+	t.Parallel()
+
+	repo := newTestRepository(t)
+
+	commitFiles(t, repo, "feat: a", map[string]string{"charts/a/.releaser.yaml": "changelog:\n  sort: true\n"})
+
+	conf := config.New()
+	conf.ReleaseAs["b"] = semver.MustParse("2.0.0")
+
+	var logs bytes.Buffer
+
+	err := newTestReleaser(repo, conf, &logs).Run(context.Background())
+	require.ErrorIs(t, err, releaser.ErrReleaseAsUnknownProject)
+	require.ErrorContains(t, err, "b:")
+
+	// no project is released.
+	assert.NotContains(t, logs.String(), "release summary")
+}
+
+func TestRunInvalidProject(t *testing.T) {
+	t.Parallel()
+
+	remote := t.TempDir()
+
+	_, err := git.PlainInit(remote, true)
+	require.NoError(t, err)
+
+	repo := newTestRepository(t)
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{remote}})
+	require.NoError(t, err)
+
+	commitFiles(t, repo, "chore: init", map[string]string{
+		"charts/a/.releaser.yaml": "changelog: [\n",
+		"charts/b/.releaser.yaml": "changelog:\n  sort: true\n",
+	})
+	commitFiles(t, repo, "fix: b", map[string]string{"charts/b/values.yaml": "fixed: true\n"})
+
+	require.NoError(t, repo.Push(&git.PushOptions{RefSpecs: []gitconfig.RefSpec{"refs/heads/*:refs/heads/*"}}))
+
+	var logs bytes.Buffer
+
+	err = newTestReleaser(repo, config.New(), &logs).Run(context.Background())
+
+	// the invalid project a does not stop the release of b.
+	require.ErrorContains(t, err, "a: failed to initialize project")
+	assert.NotContains(t, err.Error(), "b:")
+
+	assert.Contains(t, logs.String(), `"project":"a","status":"failed"`)
+	assert.Contains(t, logs.String(), `"project":"b","status":"released","version":"0.0.1"`)
+	assert.Contains(t, logs.String(), `"released":1,"skipped":0,"failed":1,"message":"release summary"`)
+}
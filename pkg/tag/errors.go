@@ -0,0 +1,5 @@
+package tag
+
+import "errors"
+
+var ErrMissingVersionPlaceholder = errors.New("tag pattern must contain {version} or {major}, {minor} and {patch}")
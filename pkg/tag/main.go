@@ -0,0 +1,200 @@
+package tag
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const shortShaLength = 7
+
+// Pattern represents a git tag pattern.
+//
+// Supported placeholders:
+//   - {project}: name of the project
+//   - {version}: full version including pre-release and build metadata, prefixed with the version prefix
+//   - {major}, {minor}, {patch}: parts of the version
+//   - {prerelease}: pre-release of the version, including the leading "-", if any
+//   - {sha}, {shortSha}: hash of the tagged commit
+type Pattern struct {
+	pattern       string
+	versionPrefix string
+	project       string
+	regexp        *regexp.Regexp
+}
+
+var (
+	regexpPlaceholder = regexp.MustCompile(`\{(project|version|major|minor|patch|prerelease|sha|shortSha)}`)
+
+	placeholderRegexps = map[string]string{
+		"version":    `\d+\.\d+\.\d+(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?`,
+		"major":      `\d+`,
+		"minor":      `\d+`,
+		"patch":      `\d+`,
+		"prerelease": `(?:-[0-9A-Za-z.-]+)?`,
+		"sha":        `[0-9a-f]{40}`,
+		"shortSha":   `[0-9a-f]{7,40}`,
+	}
+
+	// looseVersionRegexp matches {version} without version prefix, like the tag parsing of earlier releases,
+	// e.g. v1.2.3 or 1.2.
+	looseVersionRegexp = `v?\d+(?:\.\d+){0,2}(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?`
+
+	// regexpLeadingZero matches a leading zero in the major, minor or patch part of a loose version.
+	regexpLeadingZero = regexp.MustCompile(`^v?(?:\d+\.){0,2}0\d`)
+)
+
+// New returns a new tag pattern for the given project.
+// The versionPrefix, e.g. "v", is added to {version} on format and stripped on parse.
+// Without versionPrefix, {version} parses loose versions as well, e.g. v1.2.3 or 1.2, to keep existing tags.
+func New(pattern, versionPrefix, project string) (Pattern, error) {
+	if !strings.Contains(pattern, "{version}") &&
+		!(strings.Contains(pattern, "{major}") && strings.Contains(pattern, "{minor}") && strings.Contains(pattern, "{patch}")) {
+		return Pattern{}, fmt.Errorf("%q: %w", pattern, ErrMissingVersionPlaceholder)
+	}
+
+	tagPattern := Pattern{pattern: pattern, versionPrefix: versionPrefix, project: project}
+
+	tagRegexp, err := regexp.Compile(tagPattern.regexpString())
+	if err != nil {
+		return Pattern{}, fmt.Errorf("failed to compile tag pattern %q: %w", pattern, err)
+	}
+
+	tagPattern.regexp = tagRegexp
+
+	return tagPattern, nil
+}
+
+// String returns the raw pattern.
+func (p Pattern) String() string {
+	return p.pattern
+}
+
+// HasCommitHash reports whether the pattern contains {sha} or {shortSha}.
+// Such tag names are only known once the tagged commit exists.
+func (p Pattern) HasCommitHash() bool {
+	return strings.Contains(p.pattern, "{sha}") || strings.Contains(p.pattern, "{shortSha}")
+}
+
+// Format returns the tag name for the given version and commit hash.
+func (p Pattern) Format(version semver.Version, commitHash string) string {
+	prerelease := ""
+	if version.Prerelease() != "" {
+		prerelease = "-" + version.Prerelease()
+	}
+
+	shortSha := commitHash
+	if len(shortSha) > shortShaLength {
+		shortSha = shortSha[:shortShaLength]
+	}
+
+	return regexpPlaceholder.ReplaceAllStringFunc(p.pattern, func(placeholder string) string {
+		switch placeholder {
+		case "{project}":
+			return p.project
+		case "{version}":
+			return p.versionPrefix + version.String()
+		case "{major}":
+			return fmt.Sprint(version.Major())
+		case "{minor}":
+			return fmt.Sprint(version.Minor())
+		case "{patch}":
+			return fmt.Sprint(version.Patch())
+		case "{prerelease}":
+			return prerelease
+		case "{sha}":
+			return commitHash
+		case "{shortSha}":
+			return shortSha
+		default:
+			return placeholder
+		}
+	})
+}
+
+// regexpString returns a regular expression matching all tags of the project.
+// The version parts are captured as named groups.
+func (p Pattern) regexpString() string {
+	var sb strings.Builder
+
+	seen := map[string]bool{}
+	lastIndex := 0
+
+	sb.WriteString("^")
+
+	for _, match := range regexpPlaceholder.FindAllStringSubmatchIndex(p.pattern, -1) {
+		sb.WriteString(regexp.QuoteMeta(p.pattern[lastIndex:match[0]]))
+
+		name := p.pattern[match[2]:match[3]]
+		lastIndex = match[1]
+
+		if name == "project" {
+			sb.WriteString(regexp.QuoteMeta(p.project))
+
+			continue
+		}
+
+		if name == "version" {
+			sb.WriteString(regexp.QuoteMeta(p.versionPrefix))
+		}
+
+		placeholderRegexp := placeholderRegexps[name]
+		if name == "version" && p.versionPrefix == "" {
+			placeholderRegexp = looseVersionRegexp
+		}
+
+		// go regexp does not support duplicate group names.
+		if seen[name] {
+			sb.WriteString("(?:" + placeholderRegexp + ")")
+		} else {
+			sb.WriteString("(?P<" + name + ">" + placeholderRegexp + ")")
+		}
+
+		seen[name] = true
+	}
+
+	sb.WriteString(regexp.QuoteMeta(p.pattern[lastIndex:]))
+	sb.WriteString("$")
+
+	return sb.String()
+}
+
+// Parse returns the version of the tag. If the tag does not match the pattern, ok is false.
+func (p Pattern) Parse(tag string) (*semver.Version, bool, error) {
+	match := p.regexp.FindStringSubmatch(tag)
+	if match == nil {
+		return nil, false, nil
+	}
+
+	groups := map[string]string{}
+
+	for i, name := range p.regexp.SubexpNames() {
+		if name != "" {
+			groups[name] = match[i]
+		}
+	}
+
+	versionString, ok := groups["version"]
+	if !ok {
+		versionString = fmt.Sprintf("%s.%s.%s%s", groups["major"], groups["minor"], groups["patch"], groups["prerelease"])
+	}
+
+	parseVersion := semver.StrictNewVersion
+	if ok && p.versionPrefix == "" {
+		// the loose parser accepts leading zeros, which are invalid like in strict versions.
+		if regexpLeadingZero.MatchString(versionString) {
+			return nil, false, fmt.Errorf("failed to parse version %q from tag %q: %w", versionString, tag, semver.ErrSegmentStartsZero)
+		}
+
+		parseVersion = semver.NewVersion
+	}
+
+	version, err := parseVersion(versionString)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to parse version %q from tag %q: %w", versionString, tag, err)
+	}
+
+	return version, true, nil
+}
@@ -0,0 +1,108 @@
+package tag_test
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/tag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPattern(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name          string
+		pattern       string
+		versionPrefix string
+		version       string
+		expectedTag   string
+	}{
+		{"default", "{project}/{version}", "", "1.2.3", "app/1.2.3"},
+		{"version prefix", "{project}/{version}", "v", "1.2.3", "app/v1.2.3"},
+		{"prerelease", "{project}-{version}", "v", "1.2.3-rc.1", "app-v1.2.3-rc.1"},
+		{"parts", "{project}/{major}.{minor}.{patch}{prerelease}", "", "1.2.3-rc.1", "app/1.2.3-rc.1"},
+		{"parts without prerelease", "{project}/{major}.{minor}.{patch}{prerelease}", "", "1.2.3", "app/1.2.3"},
+		{"build metadata", "{project}/{version}+{shortSha}", "", "1.2.3", "app/1.2.3+0123456"},
+		{"version only", "v{version}", "", "1.2.3", "v1.2.3"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			pattern, err := tag.New(tc.pattern, tc.versionPrefix, "app")
+			require.NoError(t, err)
+
+			tagName := pattern.Format(*semver.MustParse(tc.version), "0123456789abcdef0123456789abcdef01234567")
+			assert.Equal(t, tc.expectedTag, tagName)
+
+			version, ok, err := pattern.Parse(tagName)
+			require.NoError(t, err)
+			require.True(t, ok)
+			assert.Equal(t, tc.version, version.String())
+
+			assert.Equal(t, tc.pattern == "{project}/{version}+{shortSha}", pattern.HasCommitHash())
+
+			otherPattern, err := tag.New(tc.pattern, tc.versionPrefix, "other")
+			require.NoError(t, err)
+
+			_, ok, err = otherPattern.Parse(tagName)
+			require.NoError(t, err)
+			assert.Equal(t, tc.pattern == "v{version}", ok)
+		})
+	}
+}
+
+func TestPatternNoMatch(t *testing.T) {
+	t.Parallel()
+
+	pattern, err := tag.New("{project}/{version}", "v", "app")
+	require.NoError(t, err)
+
+	for _, tagName := range []string{"app/1.2.3", "app/v1.2", "my-app/v1.2.3", "app/v1.2.3/extra"} {
+		_, ok, err := pattern.Parse(tagName)
+		require.NoError(t, err)
+		assert.False(t, ok, tagName)
+	}
+}
+
+func TestPatternMissingVersion(t *testing.T) {
+	t.Parallel()
+
+	_, err := tag.New("{project}/{major}.{minor}", "", "app")
+	require.ErrorIs(t, err, tag.ErrMissingVersionPlaceholder)
+}
+
+func TestPatternLooseVersion(t *testing.T) {
+	t.Parallel()
+
+	pattern, err := tag.New("{project}/{version}", "", "chart")
+	require.NoError(t, err)
+
+	// tags of earlier releases, which parsed the version loosely.
+	for tagName, expected := range map[string]string{
+		"chart/v1.2.3":      "1.2.3",
+		"chart/1.2":         "1.2.0",
+		"chart/v2":          "2.0.0",
+		"chart/1.2.3-rc.1":  "1.2.3-rc.1",
+		"chart/v10.20.30+b": "10.20.30+b",
+	} {
+		version, ok, err := pattern.Parse(tagName)
+		require.NoError(t, err, tagName)
+		require.True(t, ok, tagName)
+		assert.Equal(t, expected, version.String(), tagName)
+	}
+
+	for _, tagName := range []string{"chart/01.2.3", "chart/v1.02.3", "chart/1.2.03"} {
+		_, _, err := pattern.Parse(tagName)
+		require.ErrorIs(t, err, semver.ErrSegmentStartsZero, tagName)
+	}
+
+	// with a version prefix, the version must be strict.
+	prefixed, err := tag.New("{project}/{version}", "v", "chart")
+	require.NoError(t, err)
+
+	_, ok, err := prefixed.Parse("chart/v1.2")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
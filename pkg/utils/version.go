@@ -5,7 +5,20 @@ import (
 	cc "github.com/leodido/go-conventionalcommits"
 )
 
-func IncrementSemVerVersion(version *semver.Version, bump cc.VersionBump) semver.Version {
+// IncrementSemVerVersion increments the version based on the bump.
+// If initialDevelopment is enabled and the major version is 0, breaking changes
+// increment the minor version and features increment the patch version.
+func IncrementSemVerVersion(version *semver.Version, bump cc.VersionBump, initialDevelopment bool) semver.Version {
+	if initialDevelopment && version.Major() == 0 {
+		switch bump {
+		case cc.MajorVersion:
+			bump = cc.MinorVersion
+		case cc.MinorVersion:
+			bump = cc.PatchVersion
+		case cc.PatchVersion, cc.UnknownVersion:
+		}
+	}
+
 	switch bump {
 	case cc.MajorVersion:
 		return version.IncMajor()
@@ -0,0 +1,39 @@
+package utils_test
+
+import (
+	"testing"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/jkroepke/semantic-releaser/pkg/utils"
+	cc "github.com/leodido/go-conventionalcommits"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIncrementSemVerVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		name               string
+		version            string
+		bump               cc.VersionBump
+		initialDevelopment bool
+		expected           string
+	}{
+		{"major", "0.4.2", cc.MajorVersion, false, "1.0.0"},
+		{"minor", "0.4.2", cc.MinorVersion, false, "0.5.0"},
+		{"patch", "0.4.2", cc.PatchVersion, false, "0.4.3"},
+		{"unknown", "0.4.2", cc.UnknownVersion, false, "0.4.2"},
+		{"initial development major", "0.4.2", cc.MajorVersion, true, "0.5.0"},
+		{"initial development minor", "0.4.2", cc.MinorVersion, true, "0.4.3"},
+		{"initial development patch", "0.4.2", cc.PatchVersion, true, "0.4.3"},
+		{"initial development unknown", "0.4.2", cc.UnknownVersion, true, "0.4.2"},
+		{"initial development after 1.0.0", "1.4.2", cc.MajorVersion, true, "2.0.0"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			version := utils.IncrementSemVerVersion(semver.MustParse(tc.version), tc.bump, tc.initialDevelopment)
+			assert.Equal(t, tc.expected, version.String())
+		})
+	}
+}